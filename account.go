@@ -2,16 +2,29 @@ package ethclient
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/hex"
 	"io/ioutil"
 	"math/big"
-	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pborman/uuid"
+)
+
+type ScryptParams struct {
+	N int
+	P int
+}
+
+var (
+	StandardScrypt = ScryptParams{keystore.StandardScryptN, keystore.StandardScryptP}
+	LightScrypt    = ScryptParams{keystore.LightScryptN, keystore.LightScryptP}
 )
 
 type Account struct {
@@ -20,7 +33,16 @@ type Account struct {
 }
 
 func NewAccount(keyFile, password string) (*Account, error) {
-	key, err := importKey(keyFile, password)
+	json, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewAccountFromJSON(json, password)
+}
+
+// NewAccountFromJSON decrypts a key file content in memory
+func NewAccountFromJSON(json []byte, password string) (*Account, error) {
+	key, err := keystore.DecryptKey(json, password)
 	if err != nil {
 		return nil, err
 	}
@@ -31,23 +53,78 @@ func NewAccount(keyFile, password string) (*Account, error) {
 	}, nil
 }
 
-func importKey(keyFile, password string) (*keystore.Key, error) {
-	f, err := os.Open(keyFile)
+// NewAccountFromKey wraps a private key, the account has no password until
+// SetPassword is called
+func NewAccountFromKey(privateKey *ecdsa.PrivateKey) *Account {
+	return &Account{
+		key: &keystore.Key{
+			Id:         uuid.NewRandom(),
+			Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+			PrivateKey: privateKey,
+		},
+	}
+}
+
+func NewAccountFromHexKey(hexKey string) (*Account, error) {
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
-	json, err := ioutil.ReadAll(f)
+	return NewAccountFromKey(privateKey), nil
+}
+
+// GenerateAccount creates an account with a random key which only lives in
+// memory unless it's exported
+func GenerateAccount() (*Account, error) {
+	privateKey, err := crypto.GenerateKey()
 	if err != nil {
 		return nil, err
 	}
-	return keystore.DecryptKey(json, password)
+	return NewAccountFromKey(privateKey), nil
+}
+
+func (account *Account) SetPassword(password string) {
+	account.password = password
+}
+
+// EncryptKey returns the key file content encrypted with the account
+// password
+func (account *Account) EncryptKey(params ScryptParams) ([]byte, error) {
+	return keystore.EncryptKey(account.key, account.password, params.N, params.P)
+}
+
+func (account *Account) ExportKeyFile(keyFile string, params ScryptParams) error {
+	json, err := account.EncryptKey(params)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(keyFile, json)
+}
+
+// InstallKeyFile writes the key file into a keystore folder like the one of
+// geth, and returns the file path. Light scrypt parameters keep unlocking in
+// the node fast
+func (account *Account) InstallKeyFile(keystoreDir string) (string, error) {
+	keyFile := filepath.Join(keystoreDir, strings.Join([]string{account.Address().Hex(), "key"}, "."))
+	return keyFile, account.ExportKeyFile(keyFile, LightScrypt)
 }
 
 func (account *Account) Address() common.Address {
 	return account.key.Address
 }
 
+func (account *Account) SignTx(chainID *big.Int, tx *types.Transaction) (*types.Transaction, error) {
+	signer := types.NewEIP155Signer(chainID)
+	signature, err := crypto.Sign(signer.Hash(tx).Bytes(), account.key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, signature)
+}
+
+// SignTransaction signs tx with signer.
+//
+// Deprecated: use SignTx, which signs with an eip155 signer
 func (account *Account) SignTransaction(signer types.Signer, tx *types.Transaction) (*types.Transaction, error) {
 	signature, err := crypto.Sign(signer.Hash(tx).Bytes(), account.key.PrivateKey)
 	if err != nil {
@@ -60,18 +137,41 @@ func (c *Client) AccountNextNonce(address common.Address) (uint64, error) {
 	return c.PendingNonceAt(context.Background(), address)
 }
 
-func (c *Client) Transfer(from *Account, destAccount common.Address, nonce uint64, value *big.Int) error {
-	rawTx := types.NewTransaction(nonce, destAccount, value, nil)
-	signedTx, err := from.SignTransaction(types.HomesteadSigner{}, rawTx)
+func (c *Client) Transfer(from Signer, destAccount common.Address, nonce uint64, value *big.Int) (*types.Transaction, error) {
+	return c.TransferContext(context.Background(), from, destAccount, nonce, value)
+}
+
+func (c *Client) TransferContext(ctx context.Context, from Signer, destAccount common.Address, nonce uint64, value *big.Int) (*types.Transaction, error) {
+	return c.TransferWithOpts(ctx, from, destAccount, nonce, &SendOpts{Value: value})
+}
+
+func (c *Client) TransferWithOpts(ctx context.Context, from Signer, destAccount common.Address, nonce uint64, opts *SendOpts) (*types.Transaction, error) {
+	chainID, err := c.ChainID(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return c.SendTransaction(context.Background(), signedTx)
+	rawTx, err := c.newTransaction(ctx, from.Address(), &destAccount, nonce, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := signTx(ctx, from, chainID, rawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.SendTransaction(ctx, signedTx); err != nil {
+		return nil, err
+	}
+	return signedTx, nil
 }
 
 func (c *Client) GetBalance(destAccount common.Address) (*big.Int, error) {
-	return c.BalanceAt(destAccount, nil)
+	return c.GetBalanceContext(context.Background(), destAccount)
+}
+
+func (c *Client) GetBalanceContext(ctx context.Context, destAccount common.Address) (*big.Int, error) {
+	return c.BalanceAtContext(ctx, destAccount, nil)
 }
 
 func (account *Account) PrivateKey() string {
@@ -82,6 +182,10 @@ func (account *Account) Password() string {
 	return account.password
 }
 
-func (account *Account) Transactor() *bind.TransactOpts {
-	return bind.NewKeyedTransactor(account.key.PrivateKey)
+func (account *Account) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, account.key.PrivateKey)
+}
+
+func (account *Account) Transactor(chainID *big.Int) *bind.TransactOpts {
+	return signerTransactor(context.Background(), account, chainID)
 }
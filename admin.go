@@ -1,22 +1,27 @@
 package ethclient
 
 import (
+	"context"
+	"strings"
+
 	"github.com/ethereum/go-ethereum/p2p"
 )
 
 func (ec *Client) AddPeer(url string) (bool, error) {
-	var succeed bool
-	err := ec.c.Call(&succeed, "admin_addPeer", url)
-	if err != nil {
-		return false, err
-	} else {
-		return succeed, nil
-	}
+	return ec.AddPeerContext(context.Background(), url)
+}
+
+func (ec *Client) AddPeerContext(ctx context.Context, url string) (bool, error) {
+	return ec.callBool(ctx, "admin_addPeer", url)
 }
 
 func (ec *Client) Peers() ([]*p2p.PeerInfo, error) {
+	return ec.PeersContext(context.Background())
+}
+
+func (ec *Client) PeersContext(ctx context.Context) ([]*p2p.PeerInfo, error) {
 	var peers []*p2p.PeerInfo
-	err := ec.c.Call(&peers, "admin_peers")
+	err := ec.call(ctx, &peers, "admin_peers")
 	if err != nil {
 		return nil, err
 	} else {
@@ -25,11 +30,143 @@ func (ec *Client) Peers() ([]*p2p.PeerInfo, error) {
 }
 
 func (ec *Client) NodeInfo() (*p2p.NodeInfo, error) {
+	return ec.NodeInfoContext(context.Background())
+}
+
+func (ec *Client) NodeInfoContext(ctx context.Context) (*p2p.NodeInfo, error) {
 	var nodeInfo p2p.NodeInfo
-	err := ec.c.Call(&nodeInfo, "admin_nodeInfo")
+	err := ec.call(ctx, &nodeInfo, "admin_nodeInfo")
 	if err != nil {
 		return nil, err
 	} else {
 		return &nodeInfo, nil
 	}
 }
+
+func (ec *Client) RemovePeer(url string) (bool, error) {
+	return ec.RemovePeerContext(context.Background(), url)
+}
+
+func (ec *Client) RemovePeerContext(ctx context.Context, url string) (bool, error) {
+	return ec.callBool(ctx, "admin_removePeer", url)
+}
+
+// AddTrustedPeer lets the peer connect even when the node is full. The
+// trusted peer methods need geth 1.8 or later, older nodes answer that the
+// method doesn't exist
+func (ec *Client) AddTrustedPeer(url string) (bool, error) {
+	return ec.AddTrustedPeerContext(context.Background(), url)
+}
+
+func (ec *Client) AddTrustedPeerContext(ctx context.Context, url string) (bool, error) {
+	return ec.callBool(ctx, "admin_addTrustedPeer", url)
+}
+
+// RemoveTrustedPeer needs geth 1.8 or later, like AddTrustedPeer
+func (ec *Client) RemoveTrustedPeer(url string) (bool, error) {
+	return ec.RemoveTrustedPeerContext(context.Background(), url)
+}
+
+func (ec *Client) RemoveTrustedPeerContext(ctx context.Context, url string) (bool, error) {
+	return ec.callBool(ctx, "admin_removeTrustedPeer", url)
+}
+
+func (ec *Client) Datadir() (string, error) {
+	return ec.DatadirContext(context.Background())
+}
+
+func (ec *Client) DatadirContext(ctx context.Context) (string, error) {
+	var datadir string
+	err := ec.call(ctx, &datadir, "admin_datadir")
+	return datadir, err
+}
+
+// EndpointConfig is used to start rpc or ws endpoint, zero fields use the
+// node's defaults
+type EndpointConfig struct {
+	Host string
+	Port int
+	// cors domains for rpc, allowed origins for ws
+	Origins []string
+	APIs    []string
+}
+
+func (conf *EndpointConfig) args() []interface{} {
+	var host, origins, apis *string
+	var port *int
+	if conf.Host != "" {
+		host = &conf.Host
+	}
+	if conf.Port != 0 {
+		port = &conf.Port
+	}
+	if len(conf.Origins) > 0 {
+		joined := strings.Join(conf.Origins, ",")
+		origins = &joined
+	}
+	if len(conf.APIs) > 0 {
+		joined := strings.Join(conf.APIs, ",")
+		apis = &joined
+	}
+	return []interface{}{host, port, origins, apis}
+}
+
+func (ec *Client) StartRPC(conf EndpointConfig) (bool, error) {
+	return ec.StartRPCContext(context.Background(), conf)
+}
+
+func (ec *Client) StartRPCContext(ctx context.Context, conf EndpointConfig) (bool, error) {
+	return ec.callBool(ctx, "admin_startRPC", conf.args()...)
+}
+
+func (ec *Client) StopRPC() (bool, error) {
+	return ec.StopRPCContext(context.Background())
+}
+
+func (ec *Client) StopRPCContext(ctx context.Context) (bool, error) {
+	return ec.callBool(ctx, "admin_stopRPC")
+}
+
+func (ec *Client) StartWS(conf EndpointConfig) (bool, error) {
+	return ec.StartWSContext(context.Background(), conf)
+}
+
+func (ec *Client) StartWSContext(ctx context.Context, conf EndpointConfig) (bool, error) {
+	return ec.callBool(ctx, "admin_startWS", conf.args()...)
+}
+
+func (ec *Client) StopWS() (bool, error) {
+	return ec.StopWSContext(context.Background())
+}
+
+func (ec *Client) StopWSContext(ctx context.Context) (bool, error) {
+	return ec.callBool(ctx, "admin_stopWS")
+}
+
+// ExportChain writes the chain into file on the node's machine
+func (ec *Client) ExportChain(file string) (bool, error) {
+	return ec.ExportChainContext(context.Background(), file)
+}
+
+func (ec *Client) ExportChainContext(ctx context.Context, file string) (bool, error) {
+	return ec.callBool(ctx, "admin_exportChain", file)
+}
+
+// ImportChain reads the chain from file on the node's machine
+func (ec *Client) ImportChain(file string) (bool, error) {
+	return ec.ImportChainContext(context.Background(), file)
+}
+
+func (ec *Client) ImportChainContext(ctx context.Context, file string) (bool, error) {
+	return ec.callBool(ctx, "admin_importChain", file)
+}
+
+func (ec *Client) callBool(ctx context.Context, method string, args ...interface{}) (bool, error) {
+	var succeed bool
+	err := ec.call(ctx, &succeed, method, args...)
+	if err != nil {
+		return false, err
+	} else {
+		return succeed, nil
+	}
+}
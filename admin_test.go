@@ -0,0 +1,127 @@
+package ethclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// NodeAdminService has the admin methods of geth 1.7, calls records the
+// arguments of each call
+type NodeAdminService struct {
+	calls map[string][]interface{}
+}
+
+func (s *NodeAdminService) record(method string, args ...interface{}) bool {
+	if s.calls == nil {
+		s.calls = make(map[string][]interface{})
+	}
+	s.calls[method] = args
+	return true
+}
+
+func (s *NodeAdminService) RemovePeer(url string) bool { return s.record("removePeer", url) }
+
+func (s *NodeAdminService) StartRPC(host *string, port *int, cors *string, apis *string) bool {
+	return s.record("startRPC", host, port, cors, apis)
+}
+
+func (s *NodeAdminService) StopRPC() bool { return s.record("stopRPC") }
+
+func (s *NodeAdminService) StartWS(host *string, port *int, allowedOrigins *string, apis *string) bool {
+	return s.record("startWS", host, port, allowedOrigins, apis)
+}
+
+func (s *NodeAdminService) StopWS() bool                 { return s.record("stopWS") }
+func (s *NodeAdminService) ExportChain(file string) bool { return s.record("exportChain", file) }
+func (s *NodeAdminService) ImportChain(file string) bool { return s.record("importChain", file) }
+
+// TrustedAdminService adds the trusted peer methods of geth 1.8
+type TrustedAdminService struct {
+	*NodeAdminService
+}
+
+func (s *TrustedAdminService) AddTrustedPeer(url string) bool {
+	return s.record("addTrustedPeer", url)
+}
+
+func (s *TrustedAdminService) RemoveTrustedPeer(url string) bool {
+	return s.record("removeTrustedPeer", url)
+}
+
+type NodeDebugService struct {
+	head      uint64
+	verbosity int
+}
+
+func (s *NodeDebugService) SetHead(number hexutil.Uint64) { s.head = uint64(number) }
+func (s *NodeDebugService) Verbosity(level int)           { s.verbosity = level }
+
+const testEnode = "enode://6f8a80d14311c39f35f516fa664deaaaa13e85b2f7493f37f6144d86991ec012937307647bd3b9a82abe2974e1407241d54947bbb39763a4cac9f77166ad92a0@127.0.0.1:30303"
+
+func TestAdminEndpoints(t *testing.T) {
+	admin := &NodeAdminService{}
+	client := newTestClient(t, map[string]interface{}{"admin": admin})
+	ctx := context.Background()
+
+	if ok, err := client.StartRPCContext(ctx, EndpointConfig{Port: 8545, Origins: []string{"a", "b"}}); !ok || err != nil {
+		t.Fatalf("start rpc: %v %v", ok, err)
+	}
+	args := admin.calls["startRPC"]
+	// the node takes a null pointer as its default
+	if args[0].(*string) != nil || *args[1].(*int) != 8545 || *args[2].(*string) != "a,b" || args[3].(*string) != nil {
+		t.Errorf("start rpc arguments mismatch: %v", args)
+	}
+
+	if ok, err := client.StartWSContext(ctx, EndpointConfig{Host: "0.0.0.0", APIs: []string{"eth", "net"}}); !ok || err != nil {
+		t.Fatalf("start ws: %v %v", ok, err)
+	}
+	args = admin.calls["startWS"]
+	if *args[0].(*string) != "0.0.0.0" || args[1].(*int) != nil || args[2].(*string) != nil || *args[3].(*string) != "eth,net" {
+		t.Errorf("start ws arguments mismatch: %v", args)
+	}
+
+	for method, call := range map[string]func() (bool, error){
+		"stopRPC":     client.StopRPC,
+		"stopWS":      client.StopWS,
+		"removePeer":  func() (bool, error) { return client.RemovePeer(testEnode) },
+		"exportChain": func() (bool, error) { return client.ExportChain("/tmp/chain") },
+		"importChain": func() (bool, error) { return client.ImportChain("/tmp/chain") },
+	} {
+		if ok, err := call(); !ok || err != nil {
+			t.Errorf("%s: %v %v", method, ok, err)
+		} else if _, called := admin.calls[method]; !called {
+			t.Errorf("%s isn't called", method)
+		}
+	}
+}
+
+func TestTrustedPeers(t *testing.T) {
+	// geth 1.7 has no trusted peer methods
+	client := newTestClient(t, map[string]interface{}{"admin": &NodeAdminService{}})
+	if _, err := client.AddTrustedPeer(testEnode); err == nil || !isMethodNotFound(err) {
+		t.Errorf("error mismatch: have %v, want method not found", err)
+	}
+
+	admin := &TrustedAdminService{&NodeAdminService{}}
+	client = newTestClient(t, map[string]interface{}{"admin": admin})
+	if ok, err := client.AddTrustedPeer(testEnode); !ok || err != nil || admin.calls["addTrustedPeer"][0] != testEnode {
+		t.Errorf("add trusted peer: %v %v", ok, err)
+	}
+	if ok, err := client.RemoveTrustedPeer(testEnode); !ok || err != nil || admin.calls["removeTrustedPeer"][0] != testEnode {
+		t.Errorf("remove trusted peer: %v %v", ok, err)
+	}
+}
+
+func TestDebugNamespace(t *testing.T) {
+	debug := &NodeDebugService{}
+	client := newTestClient(t, map[string]interface{}{"debug": debug})
+
+	if err := client.SetHead(0x1234); err != nil || debug.head != 0x1234 {
+		t.Errorf("set head: have %d %v, want %d", debug.head, err, 0x1234)
+	}
+	if err := client.Verbosity(4); err != nil || debug.verbosity != 4 {
+		t.Errorf("verbosity: have %d %v, want 4", debug.verbosity, err)
+	}
+}
@@ -0,0 +1,297 @@
+package ethclient
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrInvalidArtifact = errors.New("invalid contract artifact")
+	ErrUnlinkedLibrary = errors.New("library isn't linked")
+	ErrUnknownContract = errors.New("contract isn't in artifact")
+	// hex never has _, so a placeholder is __ with the next 38 chars
+	libraryPlaceholders = regexp.MustCompile(`__[$A-Za-z0-9_.:/-]{38}`)
+)
+
+// LinkReference is the position of a library address in bytecode, in bytes
+type LinkReference struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// Artifact is a compiled contract, bytecode is hex without 0x and may
+// contain library placeholders which are replaced by Code
+type Artifact struct {
+	ContractName     string
+	ABI              abi.ABI
+	Bytecode         string
+	DeployedBytecode string
+	// library name, like Lib or contracts/Lib.sol:Lib, to its positions
+	LinkReferences         map[string][]LinkReference
+	DeployedLinkReferences map[string][]LinkReference
+}
+
+func NewArtifact(contractName, api, bytecode string) (*Artifact, error) {
+	contractABI, err := ABIFromString(api)
+	if err != nil {
+		return nil, err
+	}
+	return &Artifact{
+		ContractName: contractName,
+		ABI:          contractABI,
+		Bytecode:     strings.TrimPrefix(bytecode, "0x"),
+	}, nil
+}
+
+// LoadArtifact reads a truffle or hardhat artifact
+func LoadArtifact(fileName string) (*Artifact, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return ParseArtifact(data)
+}
+
+func ParseArtifact(data []byte) (*Artifact, error) {
+	var raw struct {
+		ContractName           string                                `json:"contractName"`
+		SourceName             string                                `json:"sourceName"`
+		ABI                    json.RawMessage                       `json:"abi"`
+		Bytecode               string                                `json:"bytecode"`
+		DeployedBytecode       string                                `json:"deployedBytecode"`
+		LinkReferences         map[string]map[string][]LinkReference `json:"linkReferences"`
+		DeployedLinkReferences map[string]map[string][]LinkReference `json:"deployedLinkReferences"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	} else if len(raw.ABI) == 0 || raw.Bytecode == "" {
+		return nil, fmt.Errorf("%s: no abi or bytecode", ErrInvalidArtifact.Error())
+	}
+
+	contractABI, err := parseArtifactABI(raw.ABI)
+	if err != nil {
+		return nil, err
+	}
+	return &Artifact{
+		ContractName:           raw.ContractName,
+		ABI:                    contractABI,
+		Bytecode:               strings.TrimPrefix(raw.Bytecode, "0x"),
+		DeployedBytecode:       strings.TrimPrefix(raw.DeployedBytecode, "0x"),
+		LinkReferences:         flattenLinkReferences(raw.LinkReferences),
+		DeployedLinkReferences: flattenLinkReferences(raw.DeployedLinkReferences),
+	}, nil
+}
+
+// LoadCombinedJSON reads the output of solc --combined-json abi,bin,bin-runtime,
+// contracts are keyed by source:name
+func LoadCombinedJSON(fileName string) (map[string]*Artifact, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCombinedJSON(data)
+}
+
+func ParseCombinedJSON(data []byte) (map[string]*Artifact, error) {
+	var raw struct {
+		Contracts map[string]struct {
+			ABI        json.RawMessage `json:"abi"`
+			Bin        string          `json:"bin"`
+			BinRuntime string          `json:"bin-runtime"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	} else if len(raw.Contracts) == 0 {
+		return nil, fmt.Errorf("%s: no contracts", ErrInvalidArtifact.Error())
+	}
+
+	names := make([]string, 0, len(raw.Contracts))
+	for name := range raw.Contracts {
+		names = append(names, name)
+	}
+
+	artifacts := make(map[string]*Artifact)
+	for name, contract := range raw.Contracts {
+		contractABI, err := parseArtifactABI(contract.ABI)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err.Error())
+		}
+		artifact := &Artifact{
+			ContractName:     name[strings.LastIndex(name, ":")+1:],
+			ABI:              contractABI,
+			Bytecode:         strings.TrimPrefix(contract.Bin, "0x"),
+			DeployedBytecode: strings.TrimPrefix(contract.BinRuntime, "0x"),
+		}
+		artifact.LinkReferences = placeholderReferences(artifact.Bytecode, names)
+		artifact.DeployedLinkReferences = placeholderReferences(artifact.DeployedBytecode, names)
+		artifacts[name] = artifact
+	}
+	return artifacts, nil
+}
+
+// placeholderReferences finds the placeholders of the source:Lib names in
+// bytecode, so libraries can be linked by Lib though solc only knows the
+// full name
+func placeholderReferences(bytecode string, names []string) map[string][]LinkReference {
+	refs := make(map[string][]LinkReference)
+	for _, name := range names {
+		for _, placeholder := range []string{hashPlaceholder(name), legacyPlaceholder(name)} {
+			for i := 0; ; {
+				j := strings.Index(bytecode[i:], placeholder)
+				if j < 0 {
+					break
+				}
+				i += j
+				refs[name] = append(refs[name], LinkReference{Start: i / 2, Length: common.AddressLength})
+				i += len(placeholder)
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return refs
+}
+
+// CombinedJSONArtifact returns the contract with name or source:name
+func CombinedJSONArtifact(artifacts map[string]*Artifact, name string) (*Artifact, error) {
+	if artifact, ok := artifacts[name]; ok {
+		return artifact, nil
+	}
+
+	var found *Artifact
+	for _, artifact := range artifacts {
+		if artifact.ContractName == name {
+			if found != nil {
+				return nil, fmt.Errorf("%s: %s is ambiguous", ErrUnknownContract.Error(), name)
+			}
+			found = artifact
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%s: %s", ErrUnknownContract.Error(), name)
+	}
+	return found, nil
+}
+
+// abi is json array, old solc puts it in a string
+func parseArtifactABI(raw json.RawMessage) (abi.ABI, error) {
+	if len(raw) > 0 && raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return abi.ABI{}, err
+		}
+		raw = json.RawMessage(s)
+	}
+	return abi.JSON(bytes.NewReader(raw))
+}
+
+// hardhat keys link references by source then library name
+func flattenLinkReferences(refs map[string]map[string][]LinkReference) map[string][]LinkReference {
+	if len(refs) == 0 {
+		return nil
+	}
+	flat := make(map[string][]LinkReference)
+	for source, libraries := range refs {
+		for library, positions := range libraries {
+			flat[source+":"+library] = positions
+		}
+	}
+	return flat
+}
+
+// Code returns the creation bytecode linked with libraries, library could be
+// named as Lib or source:Lib
+func (a *Artifact) Code(libraries map[string]common.Address) ([]byte, error) {
+	return linkBytecode(a.Bytecode, a.LinkReferences, libraries)
+}
+
+// DeployedCode returns the runtime bytecode linked with libraries, which
+// could be compared with the code of deployed contract
+func (a *Artifact) DeployedCode(libraries map[string]common.Address) ([]byte, error) {
+	return linkBytecode(a.DeployedBytecode, a.DeployedLinkReferences, libraries)
+}
+
+func linkBytecode(bytecode string, refs map[string][]LinkReference, libraries map[string]common.Address) ([]byte, error) {
+	code := []byte(bytecode)
+	for name, positions := range refs {
+		address, ok := findLibrary(libraries, name)
+		if ok == false {
+			return nil, fmt.Errorf("%s: %s", ErrUnlinkedLibrary.Error(), name)
+		}
+		addressHex := hex.EncodeToString(address[:])
+		for _, pos := range positions {
+			if pos.Length != common.AddressLength || 2*(pos.Start+pos.Length) > len(code) {
+				return nil, fmt.Errorf("%s: link reference of %s is out of range", ErrInvalidArtifact.Error(), name)
+			}
+			copy(code[2*pos.Start:], addressHex)
+		}
+	}
+
+	// placeholders which aren't in link references
+	for name, address := range libraries {
+		addressHex := []byte(hex.EncodeToString(address[:]))
+		for _, placeholder := range libraryPlaceholderCandidates(name) {
+			code = bytes.Replace(code, []byte(placeholder), addressHex, -1)
+		}
+	}
+
+	if unlinked := libraryPlaceholders.FindAll(code, -1); len(unlinked) > 0 {
+		names := make([]string, 0, len(unlinked))
+		for _, placeholder := range unlinked {
+			names = append(names, string(placeholder))
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%s: %s", ErrUnlinkedLibrary.Error(), strings.Join(names, ","))
+	}
+
+	b, err := hex.DecodeString(string(code))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrInvalidArtifact.Error(), err.Error())
+	}
+	return b, nil
+}
+
+// link reference name is source:Lib, libraries may only name Lib
+func findLibrary(libraries map[string]common.Address, name string) (common.Address, bool) {
+	if address, ok := libraries[name]; ok {
+		return address, true
+	}
+	address, ok := libraries[name[strings.LastIndex(name, ":")+1:]]
+	return address, ok
+}
+
+// solc >= 0.5 uses the hash of source:Lib, older solc uses source:Lib and
+// truffle uses Lib in the legacy form
+func libraryPlaceholderCandidates(name string) []string {
+	candidates := []string{hashPlaceholder(name)}
+	for _, n := range []string{name, name[strings.LastIndex(name, ":")+1:]} {
+		candidates = append(candidates, legacyPlaceholder(n))
+	}
+	return candidates
+}
+
+// __$keccak256(name)[:34]$__
+func hashPlaceholder(name string) string {
+	return "__$" + hex.EncodeToString(crypto.Keccak256([]byte(name)))[:34] + "$__"
+}
+
+// __ and name truncated to 36 chars, right padded with _ to 40 chars
+func legacyPlaceholder(name string) string {
+	if len(name) > 36 {
+		name = name[:36]
+	}
+	return "__" + name + strings.Repeat("_", 38-len(name))
+}
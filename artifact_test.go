@@ -0,0 +1,116 @@
+package ethclient
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	testLibName     = "contracts/Lib.sol:Lib"
+	testLongLibName = "contracts/very/long/path/LongLibrary.sol:LongLibrary"
+)
+
+var testLibAddress = common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+func TestLibraryPlaceholders(t *testing.T) {
+	tests := []struct {
+		name        string
+		placeholder string
+	}{
+		{"Lib", "__Lib_____________________________________"},
+		// solc keeps 36 chars of the name
+		{testLongLibName, "__contracts/very/long/path/LongLibrary__"},
+	}
+	for _, test := range tests {
+		want := test.placeholder[:40]
+		if got := legacyPlaceholder(test.name); got != want {
+			t.Errorf("%s: placeholder mismatch: have %s, want %s", test.name, got, want)
+		}
+	}
+	if got := hashPlaceholder(testLibName); len(got) != 40 || !strings.HasPrefix(got, "__$") || !strings.HasSuffix(got, "$__") {
+		t.Errorf("hash placeholder is malformed: %s", got)
+	}
+}
+
+func TestArtifactLinking(t *testing.T) {
+	address := hex.EncodeToString(testLibAddress[:])
+	hardhatJSON, _ := json.Marshal(map[string]interface{}{
+		"contractName": "User",
+		"sourceName":   "contracts/User.sol",
+		"abi":          []interface{}{},
+		"bytecode":     "0x6080" + hashPlaceholder(testLibName) + "6000",
+		"linkReferences": map[string]interface{}{
+			"contracts/Lib.sol": map[string]interface{}{
+				"Lib": []LinkReference{{Start: 2, Length: 20}},
+			},
+		},
+	})
+	hardhat, err := ParseArtifact(hardhatJSON)
+	if err != nil {
+		t.Fatalf("parse hardhat artifact: %v", err)
+	}
+
+	combinedJSON, _ := json.Marshal(map[string]interface{}{
+		"contracts": map[string]interface{}{
+			"contracts/User.sol:User": map[string]interface{}{
+				"abi": "[]",
+				"bin": "6080" + hashPlaceholder(testLibName) + "6000" + legacyPlaceholder(testLibName),
+			},
+			testLibName: map[string]interface{}{"abi": "[]", "bin": "6000"},
+		},
+	})
+	combined, err := ParseCombinedJSON(combinedJSON)
+	if err != nil {
+		t.Fatalf("parse combined json: %v", err)
+	}
+	combinedUser, err := CombinedJSONArtifact(combined, "User")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newArtifact := func(bytecode string) *Artifact {
+		artifact, err := NewArtifact("User", "[]", bytecode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return artifact
+	}
+
+	tests := []struct {
+		name      string
+		artifact  *Artifact
+		libraries map[string]common.Address
+		code      string
+		err       error
+	}{
+		{"hardhat by name", hardhat, map[string]common.Address{"Lib": testLibAddress}, "6080" + address + "6000", nil},
+		{"hardhat by full name", hardhat, map[string]common.Address{testLibName: testLibAddress}, "6080" + address + "6000", nil},
+		{"hash placeholder", newArtifact("6080" + hashPlaceholder(testLibName) + "6000"),
+			map[string]common.Address{testLibName: testLibAddress}, "6080" + address + "6000", nil},
+		{"truffle placeholder", newArtifact("6080" + legacyPlaceholder("Lib") + "6000"),
+			map[string]common.Address{"Lib": testLibAddress}, "6080" + address + "6000", nil},
+		{"truncated placeholder", newArtifact("6080" + legacyPlaceholder(testLongLibName) + "6000"),
+			map[string]common.Address{testLongLibName: testLibAddress}, "6080" + address + "6000", nil},
+		{"combined json by name", combinedUser, map[string]common.Address{"Lib": testLibAddress},
+			"6080" + address + "6000" + address, nil},
+		{"unlinked hardhat", hardhat, nil, "", ErrUnlinkedLibrary},
+		{"unlinked placeholder", newArtifact("6080" + hashPlaceholder(testLibName)),
+			map[string]common.Address{"Other": testLibAddress}, "", ErrUnlinkedLibrary},
+	}
+	for _, test := range tests {
+		code, err := test.artifact.Code(test.libraries)
+		if test.err != nil {
+			if err == nil || !strings.HasPrefix(err.Error(), test.err.Error()) {
+				t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if got := hex.EncodeToString(code); got != test.code {
+			t.Errorf("%s: code mismatch: have %s, want %s", test.name, got, test.code)
+		}
+	}
+}
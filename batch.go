@@ -0,0 +1,201 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type BlockResult struct {
+	Number uint64
+	Block  *types.Block
+	Err    error
+}
+
+type HeaderResult struct {
+	Number uint64
+	Header *types.Header
+	Err    error
+}
+
+type ReceiptResult struct {
+	TxHash  common.Hash
+	Receipt *types.Receipt
+	Err     error
+}
+
+type BalanceResult struct {
+	Account common.Address
+	Balance *big.Int
+	Err     error
+}
+
+// most requests sent in one batch, nodes limit the size of a request
+const maxBatchSize = 128
+
+// batch calls send the requests in one round trip per maxBatchSize of them.
+// The returned error is only for the whole batch, like the transport is
+// broken, every item carries its own error
+func (ec *Client) batchCall(ctx context.Context, batch []rpc.BatchElem) error {
+	for start := 0; start < len(batch); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(batch) {
+			end = len(batch)
+		}
+		if err := ec.batchChunk(ctx, batch[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ec *Client) batchChunk(ctx context.Context, batch []rpc.BatchElem) error {
+	ctx, cancel := ec.withTimeout(ctx)
+	defer cancel()
+	return ec.c.BatchCallContext(ctx, batch)
+}
+
+// BlocksByRange fetches blocks from number from to number to, both included
+func (ec *Client) BlocksByRange(ctx context.Context, from, to uint64) ([]BlockResult, error) {
+	if to < from {
+		return nil, nil
+	}
+
+	raws := make([]json.RawMessage, to-from+1)
+	batch := make([]rpc.BatchElem, len(raws))
+	for i := range batch {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(from + uint64(i)), true},
+			Result: &raws[i],
+		}
+	}
+	if err := ec.batchCall(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	results := make([]BlockResult, len(batch))
+	for i, elem := range batch {
+		results[i].Number = from + uint64(i)
+		if elem.Error != nil {
+			results[i].Err = elem.Error
+		} else {
+			results[i].Block, results[i].Err = ec.decodeBlock(ctx, raws[i])
+		}
+	}
+	return results, nil
+}
+
+func (ec *Client) HeadersByRange(ctx context.Context, from, to uint64) ([]HeaderResult, error) {
+	if to < from {
+		return nil, nil
+	}
+
+	headers := make([]*types.Header, to-from+1)
+	batch := make([]rpc.BatchElem, len(headers))
+	for i := range batch {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(from + uint64(i)), false},
+			Result: &headers[i],
+		}
+	}
+	if err := ec.batchCall(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	results := make([]HeaderResult, len(batch))
+	for i, elem := range batch {
+		results[i].Number = from + uint64(i)
+		if elem.Error != nil {
+			results[i].Err = elem.Error
+		} else if headers[i] == nil {
+			results[i].Err = ethereum.NotFound
+		} else {
+			results[i].Header = headers[i]
+		}
+	}
+	return results, nil
+}
+
+func (ec *Client) TransactionReceipts(ctx context.Context, txHashes []common.Hash) ([]ReceiptResult, error) {
+	receipts := make([]*types.Receipt, len(txHashes))
+	batch := make([]rpc.BatchElem, len(txHashes))
+	for i, hash := range txHashes {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{hash},
+			Result: &receipts[i],
+		}
+	}
+	if len(batch) == 0 {
+		return nil, nil
+	}
+	if err := ec.batchCall(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	results := make([]ReceiptResult, len(batch))
+	for i, elem := range batch {
+		results[i].TxHash = txHashes[i]
+		if elem.Error != nil {
+			results[i].Err = elem.Error
+		} else if receipts[i] == nil {
+			results[i].Err = ethereum.NotFound
+		} else {
+			results[i].Receipt = receipts[i]
+		}
+	}
+	return results, nil
+}
+
+// ReceiptsForBlock returns the receipts of all the transactions in the block
+// with hash, in the order of the transactions
+func (ec *Client) ReceiptsForBlock(ctx context.Context, hash common.Hash) ([]ReceiptResult, error) {
+	block, err := ec.BlockByHashContext(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := block.Transactions()
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	return ec.TransactionReceipts(ctx, hashes)
+}
+
+func (ec *Client) BalancesAt(ctx context.Context, accounts []common.Address, blockNumber *big.Int) ([]BalanceResult, error) {
+	balances := make([]hexutil.Big, len(accounts))
+	batch := make([]rpc.BatchElem, len(accounts))
+	for i, account := range accounts {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{account, toBlockNumArg(blockNumber)},
+			Result: &balances[i],
+		}
+	}
+	if len(batch) == 0 {
+		return nil, nil
+	}
+	if err := ec.batchCall(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	results := make([]BalanceResult, len(batch))
+	for i, elem := range batch {
+		results[i].Account = accounts[i]
+		if elem.Error != nil {
+			results[i].Err = elem.Error
+		} else {
+			results[i].Balance = (*big.Int)(&balances[i])
+		}
+	}
+	return results, nil
+}
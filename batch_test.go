@@ -0,0 +1,214 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BatchService is a chain of head+1 empty blocks, the block, account and
+// transaction named bad fail
+type BatchService struct {
+	head       uint64
+	badNumber  uint64
+	badAccount common.Address
+	badTx      common.Hash
+	receipts   map[common.Hash]bool
+}
+
+func (s *BatchService) GetBlockByNumber(number hexutil.Uint64, full bool) (map[string]interface{}, error) {
+	if uint64(number) == s.badNumber {
+		return nil, errors.New("bad block")
+	} else if uint64(number) > s.head {
+		return nil, nil
+	}
+
+	header := &types.Header{
+		Number:      new(big.Int).SetUint64(uint64(number)),
+		Difficulty:  big.NewInt(1),
+		GasLimit:    big.NewInt(4712388),
+		GasUsed:     big.NewInt(0),
+		Time:        big.NewInt(0),
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		UncleHash:   types.EmptyUncleHash,
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["hash"] = header.Hash()
+	fields["transactions"] = []interface{}{}
+	fields["uncles"] = []interface{}{}
+	return fields, nil
+}
+
+func (s *BatchService) GetBalance(account common.Address, block string) (*hexutil.Big, error) {
+	if account == s.badAccount {
+		return nil, errors.New("bad account")
+	}
+	return (*hexutil.Big)(new(big.Int).SetBytes(account[:])), nil
+}
+
+func (s *BatchService) GetTransactionReceipt(hash common.Hash) (map[string]interface{}, error) {
+	if hash == s.badTx {
+		return nil, errors.New("bad transaction")
+	} else if s.receipts[hash] == false {
+		return nil, nil
+	}
+	return map[string]interface{}{
+		"status":            "0x1",
+		"cumulativeGasUsed": "0x5208",
+		"gasUsed":           "0x5208",
+		"logsBloom":         hexutil.Encode(make([]byte, types.BloomByteLength)),
+		"logs":              []interface{}{},
+		"transactionHash":   hash,
+	}, nil
+}
+
+// newBatchTestClient counts the http requests, one per batch
+func newBatchTestClient(t *testing.T, service *BatchService) (*Client, *int32) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", service); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	var requests int32
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		srv.ServeHTTP(w, r)
+	}))
+	client, err := Dial(httpSrv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		httpSrv.Close()
+		srv.Stop()
+	})
+	return client, &requests
+}
+
+func TestBlocksByRange(t *testing.T) {
+	client, requests := newBatchTestClient(t, &BatchService{head: 250, badNumber: 7})
+
+	blocks, err := client.BlocksByRange(context.Background(), 0, 299)
+	if err != nil {
+		t.Fatalf("blocks: %v", err)
+	}
+	if len(blocks) != 300 {
+		t.Fatalf("result count mismatch: have %d, want 300", len(blocks))
+	}
+	for i, result := range blocks {
+		switch {
+		case result.Number != uint64(i):
+			t.Fatalf("result %d is block %d", i, result.Number)
+		case i == 7:
+			if result.Err == nil {
+				t.Errorf("block %d: error is missing", i)
+			}
+		case i > 250:
+			if result.Err != ethereum.NotFound {
+				t.Errorf("block %d: have %v, want %v", i, result.Err, ethereum.NotFound)
+			}
+		case result.Err != nil || result.Block.NumberU64() != uint64(i):
+			t.Errorf("block %d: have %v %v", i, result.Block, result.Err)
+		}
+	}
+	// 128, 128 and 44 blocks
+	if *requests != 3 {
+		t.Errorf("request count mismatch: have %d, want 3", *requests)
+	}
+}
+
+func TestHeadersByRange(t *testing.T) {
+	client, requests := newBatchTestClient(t, &BatchService{head: 20, badNumber: 12})
+
+	headers, err := client.HeadersByRange(context.Background(), 10, 22)
+	if err != nil {
+		t.Fatalf("headers: %v", err)
+	}
+	if len(headers) != 13 || *requests != 1 {
+		t.Fatalf("result count mismatch: have %d in %d requests", len(headers), *requests)
+	}
+	for i, result := range headers {
+		number := uint64(10 + i)
+		switch {
+		case result.Number != number:
+			t.Fatalf("result %d is header %d", i, result.Number)
+		case number == 12:
+			if result.Err == nil {
+				t.Errorf("header %d: error is missing", number)
+			}
+		case number > 20:
+			if result.Err != ethereum.NotFound {
+				t.Errorf("header %d: have %v, want %v", number, result.Err, ethereum.NotFound)
+			}
+		case result.Err != nil || result.Header.Number.Uint64() != number:
+			t.Errorf("header %d: have %v %v", number, result.Header, result.Err)
+		}
+	}
+
+	if headers, err := client.HeadersByRange(context.Background(), 5, 4); err != nil || headers != nil {
+		t.Errorf("empty range: have %v %v", headers, err)
+	}
+}
+
+func TestTransactionReceiptsAndBalances(t *testing.T) {
+	found, missing, bad := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	service := &BatchService{
+		badAccount: common.Address{2},
+		badTx:      bad,
+		receipts:   map[common.Hash]bool{found: true},
+	}
+	client, _ := newBatchTestClient(t, service)
+
+	receipts, err := client.TransactionReceipts(context.Background(), []common.Hash{missing, found, bad})
+	if err != nil {
+		t.Fatalf("receipts: %v", err)
+	}
+	if len(receipts) != 3 {
+		t.Fatalf("result count mismatch: have %d, want 3", len(receipts))
+	}
+	if receipts[0].TxHash != missing || receipts[0].Err != ethereum.NotFound {
+		t.Errorf("missing receipt: have %x %v", receipts[0].TxHash, receipts[0].Err)
+	}
+	if receipts[1].TxHash != found || receipts[1].Err != nil || receipts[1].Receipt.TxHash != found {
+		t.Errorf("found receipt: have %x %v", receipts[1].TxHash, receipts[1].Err)
+	}
+	if receipts[2].TxHash != bad || receipts[2].Err == nil {
+		t.Errorf("bad receipt: have %x %v", receipts[2].TxHash, receipts[2].Err)
+	}
+
+	accounts := []common.Address{{1}, {2}, {3}}
+	balances, err := client.BalancesAt(context.Background(), accounts, nil)
+	if err != nil {
+		t.Fatalf("balances: %v", err)
+	}
+	for i, result := range balances {
+		if result.Account != accounts[i] {
+			t.Fatalf("result %d is of %x", i, result.Account)
+		} else if i == 1 {
+			if result.Err == nil {
+				t.Errorf("balance %d: error is missing", i)
+			}
+		} else if result.Err != nil || result.Balance.Cmp(new(big.Int).SetBytes(accounts[i][:])) != 0 {
+			t.Errorf("balance %d: have %v %v", i, result.Balance, result.Err)
+		}
+	}
+}
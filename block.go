@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
@@ -16,30 +15,48 @@ import (
 )
 
 func (ec *Client) BlockNumber() (int64, error) {
+	return ec.BlockNumberContext(context.Background())
+}
+
+func (ec *Client) BlockNumberContext(ctx context.Context) (int64, error) {
 	var blockNumber rpc.BlockNumber
-	err := ec.c.Call(&blockNumber, "eth_blockNumber")
+	err := ec.call(ctx, &blockNumber, "eth_blockNumber")
 	return int64(blockNumber), err
 }
 
 func (ec *Client) BlockByHash(hash common.Hash) (*types.Block, error) {
-	return ec.getBlock(context.Background(), "eth_getBlockByHash", hash, true)
+	return ec.BlockByHashContext(context.Background(), hash)
+}
+
+func (ec *Client) BlockByHashContext(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return ec.getBlock(ctx, "eth_getBlockByHash", hash, true)
 }
 
 func (ec *Client) BlockByNumber(number *big.Int) (*types.Block, error) {
-	return ec.getBlock(context.Background(), "eth_getBlockByNumber", toBlockNumArg(number), true)
+	return ec.BlockByNumberContext(context.Background(), number)
+}
+
+func (ec *Client) BlockByNumberContext(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return ec.getBlock(ctx, "eth_getBlockByNumber", toBlockNumArg(number), true)
 }
 
 type rpcBlock struct {
 	Hash         common.Hash          `json:"hash"`
 	Transactions []*types.Transaction `json:"transactions"`
+	UncleHashes  []common.Hash        `json:"uncles"`
 }
 
 func (ec *Client) getBlock(ctx context.Context, method string, args ...interface{}) (*types.Block, error) {
 	var raw json.RawMessage
-	err := ec.c.CallContext(context.Background(), &raw, method, args...)
+	err := ec.call(ctx, &raw, method, args...)
 	if err != nil {
 		return nil, err
-	} else if len(raw) == 0 {
+	}
+	return ec.decodeBlock(ctx, raw)
+}
+
+func (ec *Client) decodeBlock(ctx context.Context, raw json.RawMessage) (*types.Block, error) {
+	if len(raw) == 0 || string(raw) == "null" {
 		return nil, ethereum.NotFound
 	}
 	// Decode header and transactions.
@@ -60,12 +77,49 @@ func (ec *Client) getBlock(ctx context.Context, method string, args ...interface
 		return nil, fmt.Errorf("server returned empty transaction list but block header indicates transactions")
 	}
 
-	return types.NewBlockWithHeader(head).WithBody(body.Transactions), nil
+	// Quick-verify uncles
+	if head.UncleHash == types.EmptyUncleHash && len(body.UncleHashes) > 0 {
+		return nil, fmt.Errorf("server returned non-empty uncle list but block header indicates no uncles")
+	}
+	if head.UncleHash != types.EmptyUncleHash && len(body.UncleHashes) == 0 {
+		return nil, fmt.Errorf("server returned empty uncle list but block header indicates uncles")
+	}
+
+	// Load uncles because they are not included in the block response.
+	var uncles []*types.Header
+	if len(body.UncleHashes) > 0 {
+		uncles = make([]*types.Header, len(body.UncleHashes))
+		reqs := make([]rpc.BatchElem, len(body.UncleHashes))
+		for i := range reqs {
+			reqs[i] = rpc.BatchElem{
+				Method: "eth_getUncleByBlockHashAndIndex",
+				Args:   []interface{}{body.Hash, hexutil.EncodeUint64(uint64(i))},
+				Result: &uncles[i],
+			}
+		}
+		if err := ec.batchCall(ctx, reqs); err != nil {
+			return nil, err
+		}
+		for i := range reqs {
+			if reqs[i].Error != nil {
+				return nil, reqs[i].Error
+			}
+			if uncles[i] == nil {
+				return nil, fmt.Errorf("got null header for uncle %d of block %x", i, body.Hash[:])
+			}
+		}
+	}
+
+	return types.NewBlockWithHeader(head).WithBody(body.Transactions, uncles), nil
 }
 
 func (ec *Client) HeaderByHash(hash common.Hash) (*types.Header, error) {
+	return ec.HeaderByHashContext(context.Background(), hash)
+}
+
+func (ec *Client) HeaderByHashContext(ctx context.Context, hash common.Hash) (*types.Header, error) {
 	var head *types.Header
-	err := ec.c.CallContext(context.Background(), &head, "eth_getBlockByHash", hash, false)
+	err := ec.call(ctx, &head, "eth_getBlockByHash", hash, false)
 	if err == nil && head == nil {
 		err = ethereum.NotFound
 	}
@@ -73,8 +127,12 @@ func (ec *Client) HeaderByHash(hash common.Hash) (*types.Header, error) {
 }
 
 func (ec *Client) HeaderByNumber(number *big.Int) (*types.Header, error) {
+	return ec.HeaderByNumberContext(context.Background(), number)
+}
+
+func (ec *Client) HeaderByNumberContext(ctx context.Context, number *big.Int) (*types.Header, error) {
 	var head *types.Header
-	err := ec.c.CallContext(context.Background(), &head, "eth_getBlockByNumber", toBlockNumArg(number), false)
+	err := ec.call(ctx, &head, "eth_getBlockByNumber", toBlockNumArg(number), false)
 	if err == nil && head == nil {
 		err = ethereum.NotFound
 	}
@@ -82,8 +140,12 @@ func (ec *Client) HeaderByNumber(number *big.Int) (*types.Header, error) {
 }
 
 func (ec *Client) TransactionByHash(hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	return ec.TransactionByHashContext(context.Background(), hash)
+}
+
+func (ec *Client) TransactionByHashContext(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
 	var raw json.RawMessage
-	err = ec.c.CallContext(context.Background(), &raw, "eth_getTransactionByHash", hash)
+	err = ec.call(ctx, &raw, "eth_getTransactionByHash", hash)
 	if err != nil {
 		return nil, false, err
 	} else if len(raw) == 0 {
@@ -102,14 +164,22 @@ func (ec *Client) TransactionByHash(hash common.Hash) (tx *types.Transaction, is
 }
 
 func (ec *Client) TransactionCount(blockHash common.Hash) (uint, error) {
+	return ec.TransactionCountContext(context.Background(), blockHash)
+}
+
+func (ec *Client) TransactionCountContext(ctx context.Context, blockHash common.Hash) (uint, error) {
 	var num hexutil.Uint
-	err := ec.c.CallContext(context.Background(), &num, "eth_getBlockTransactionCountByHash", blockHash)
+	err := ec.call(ctx, &num, "eth_getBlockTransactionCountByHash", blockHash)
 	return uint(num), err
 }
 
 func (ec *Client) TransactionInBlock(blockHash common.Hash, index uint) (*types.Transaction, error) {
+	return ec.TransactionInBlockContext(context.Background(), blockHash, index)
+}
+
+func (ec *Client) TransactionInBlockContext(ctx context.Context, blockHash common.Hash, index uint) (*types.Transaction, error) {
 	var tx *types.Transaction
-	err := ec.c.CallContext(context.Background(), &tx, "eth_getTransactionByBlockHashAndIndex", blockHash, hexutil.Uint64(index))
+	err := ec.call(ctx, &tx, "eth_getTransactionByBlockHashAndIndex", blockHash, hexutil.Uint64(index))
 	if err == nil {
 		if tx == nil {
 			return nil, ethereum.NotFound
@@ -121,8 +191,12 @@ func (ec *Client) TransactionInBlock(blockHash common.Hash, index uint) (*types.
 }
 
 func (ec *Client) TransactionReceipt(txHash common.Hash) (*types.Receipt, error) {
+	return ec.TransactionReceiptContext(context.Background(), txHash)
+}
+
+func (ec *Client) TransactionReceiptContext(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
 	var r *types.Receipt
-	err := ec.c.CallContext(context.Background(), &r, "eth_getTransactionReceipt", txHash)
+	err := ec.call(ctx, &r, "eth_getTransactionReceipt", txHash)
 	if err == nil {
 		if r == nil {
 			return nil, ethereum.NotFound
@@ -147,8 +221,12 @@ type rpcProgress struct {
 }
 
 func (ec *Client) SyncProgress() (*ethereum.SyncProgress, error) {
+	return ec.SyncProgressContext(context.Background())
+}
+
+func (ec *Client) SyncProgressContext(ctx context.Context) (*ethereum.SyncProgress, error) {
 	var raw json.RawMessage
-	if err := ec.c.CallContext(context.Background(), &raw, "eth_syncing"); err != nil {
+	if err := ec.call(ctx, &raw, "eth_syncing"); err != nil {
 		return nil, err
 	}
 	// Handle the possible response types
@@ -170,53 +248,77 @@ func (ec *Client) SyncProgress() (*ethereum.SyncProgress, error) {
 }
 
 func (ec *Client) SubscribeNewHead(ch chan<- *types.Header) (ethereum.Subscription, error) {
-	return ec.c.EthSubscribe(context.Background(), ch, "newHeads", map[string]struct{}{})
+	return ec.SubscribeNewHeadContext(context.Background(), ch)
+}
+
+func (ec *Client) SubscribeNewHeadContext(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return ec.subscribe(ctx, ch, "newHeads", map[string]struct{}{})
 }
 
 func (ec *Client) BalanceAt(account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return ec.BalanceAtContext(context.Background(), account, blockNumber)
+}
+
+func (ec *Client) BalanceAtContext(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
 	var result hexutil.Big
-	err := ec.c.CallContext(context.Background(), &result, "eth_getBalance", account, toBlockNumArg(blockNumber))
+	err := ec.call(ctx, &result, "eth_getBalance", account, toBlockNumArg(blockNumber))
 	return (*big.Int)(&result), err
 }
 
 func (ec *Client) StorageAt(account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return ec.StorageAtContext(context.Background(), account, key, blockNumber)
+}
+
+func (ec *Client) StorageAtContext(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
 	var result hexutil.Bytes
-	err := ec.c.CallContext(context.Background(), &result, "eth_getStorageAt", account, key, toBlockNumArg(blockNumber))
+	err := ec.call(ctx, &result, "eth_getStorageAt", account, key, toBlockNumArg(blockNumber))
 	return result, err
 }
 
 func (ec *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
 	var result hexutil.Bytes
-	err := ec.c.CallContext(ctx, &result, "eth_getCode", account, toBlockNumArg(blockNumber))
+	err := ec.call(ctx, &result, "eth_getCode", account, toBlockNumArg(blockNumber))
 	return result, err
 }
 
 func (ec *Client) NonceAt(account common.Address, blockNumber *big.Int) (uint64, error) {
+	return ec.NonceAtContext(context.Background(), account, blockNumber)
+}
+
+func (ec *Client) NonceAtContext(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
 	var result hexutil.Uint64
-	err := ec.c.CallContext(context.Background(), &result, "eth_getTransactionCount", account, toBlockNumArg(blockNumber))
+	err := ec.call(ctx, &result, "eth_getTransactionCount", account, toBlockNumArg(blockNumber))
 	return uint64(result), err
 }
 
 func (ec *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
 	var result hexutil.Uint64
-	err := ec.c.CallContext(ctx, &result, "eth_getTransactionCount", account, "pending")
+	err := ec.call(ctx, &result, "eth_getTransactionCount", account, "pending")
 	return uint64(result), err
 }
 
 func (ec *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
 	var result hexutil.Bytes
-	err := ec.c.CallContext(ctx, &result, "eth_getCode", account, "pending")
+	err := ec.call(ctx, &result, "eth_getCode", account, "pending")
 	return result, err
 }
 
 func (ec *Client) FilterLogs(q ethereum.FilterQuery) ([]types.Log, error) {
+	return ec.FilterLogsContext(context.Background(), q)
+}
+
+func (ec *Client) FilterLogsContext(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
 	var result []types.Log
-	err := ec.c.CallContext(context.Background(), &result, "eth_getLogs", toFilterArg(q))
+	err := ec.call(ctx, &result, "eth_getLogs", toFilterArg(q))
 	return result, err
 }
 
 func (ec *Client) SubscribeFilterLogs(q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
-	return ec.c.EthSubscribe(context.Background(), ch, "logs", toFilterArg(q))
+	return ec.SubscribeFilterLogsContext(context.Background(), q, ch)
+}
+
+func (ec *Client) SubscribeFilterLogsContext(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return ec.subscribe(ctx, ch, "logs", toFilterArg(q))
 }
 
 func toFilterArg(q ethereum.FilterQuery) interface{} {
@@ -232,18 +334,30 @@ func toFilterArg(q ethereum.FilterQuery) interface{} {
 	return arg
 }
 
+// CallContract returns *RevertError if the call reverts
 func (ec *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return ec.ethCall(ctx, msg, toBlockNumArg(blockNumber))
+}
+
+func (ec *Client) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return ec.ethCall(ctx, msg, "pending")
+}
+
+func (ec *Client) ethCall(ctx context.Context, msg ethereum.CallMsg, block string) ([]byte, error) {
 	var hex hexutil.Bytes
-	err := ec.c.CallContext(ctx, &hex, "eth_call", toCallArg(msg), toBlockNumArg(blockNumber))
+	err := ec.call(ctx, &hex, "eth_call", toCallArg(msg), block)
 	if err != nil {
-		return nil, err
+		return nil, revertFromRPCError(err)
+	}
+	if reason, ok := UnpackRevert(hex); ok {
+		return nil, &RevertError{Reason: reason}
 	}
 	return hex, nil
 }
 
 func (ec *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 	var hex hexutil.Big
-	if err := ec.c.CallContext(ctx, &hex, "eth_gasPrice"); err != nil {
+	if err := ec.call(ctx, &hex, "eth_gasPrice"); err != nil {
 		return nil, err
 	}
 	return (*big.Int)(&hex), nil
@@ -251,7 +365,7 @@ func (ec *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 
 func (ec *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (*big.Int, error) {
 	var hex hexutil.Big
-	err := ec.c.CallContext(ctx, &hex, "eth_estimateGas", toCallArg(msg))
+	err := ec.call(ctx, &hex, "eth_estimateGas", toCallArg(msg))
 	if err != nil {
 		return nil, err
 	}
@@ -259,11 +373,15 @@ func (ec *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (*big.I
 }
 
 func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := ec.checkChainID(ctx, tx); err != nil {
+		return err
+	}
+
 	data, err := rlp.EncodeToBytes(tx)
 	if err != nil {
 		return err
 	}
-	return ec.c.CallContext(ctx, nil, "eth_sendRawTransaction", common.ToHex(data))
+	return ec.call(ctx, nil, "eth_sendRawTransaction", common.ToHex(data))
 }
 
 func toCallArg(msg ethereum.CallMsg) interface{} {
@@ -277,14 +395,20 @@ func toCallArg(msg ethereum.CallMsg) interface{} {
 	if msg.Value != nil {
 		arg["value"] = (*hexutil.Big)(msg.Value)
 	}
+	if msg.Gas != nil {
+		arg["gas"] = (*hexutil.Big)(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
 	return arg
 }
 
+// SubscribeHeader can't be stopped by the caller, so it gives up after
+// subscribeHeaderRetries reconnects in a row fail and sends the last error.
+//
+// Deprecated: use SubscribeHeads, which can be unsubscribed
 func (c *Client) SubscribeHeader() (<-chan *types.Header, <-chan error) {
-	headers := make(chan *types.Header)
-	sub, err := c.SubscribeNewHead(headers)
-	if err != nil {
-		log.Fatalf("subscribe new header failed %s", err.Error())
-	}
-	return headers, sub.Err()
+	sub := c.SubscribeHeadsWithOpts(context.Background(), &HeadSubscribeOpts{MaxRetries: subscribeHeaderRetries})
+	return sub.Headers(), sub.Err()
 }
@@ -0,0 +1,257 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	ErrUnknownMethod = errors.New("method isn't in abi")
+	ErrNoCode        = errors.New("no contract code at address")
+	ErrDeployFailed  = errors.New("contract deployment failed")
+)
+
+// Contract calls methods of a deployed contract by name, arguments are
+// packed and outputs are decoded with its abi
+type Contract struct {
+	client  *Client
+	address common.Address
+	abi     abi.ABI
+}
+
+func NewContract(client *Client, address common.Address, contractABI abi.ABI) *Contract {
+	return &Contract{
+		client:  client,
+		address: address,
+		abi:     contractABI,
+	}
+}
+
+// DeployContract deploys the contract and waits until its code appears
+func DeployContract(ctx context.Context, client *Client, from Signer, api, bytecode string, params ...interface{}) (*Contract, *types.Transaction, error) {
+	artifact, err := NewArtifact("", api, bytecode)
+	if err != nil {
+		return nil, nil, err
+	}
+	return DeployArtifactContract(ctx, client, from, artifact, nil, params...)
+}
+
+// DeployArtifactContract links libraries, deploys the artifact and waits
+// until its code appears
+func DeployArtifactContract(ctx context.Context, client *Client, from Signer, artifact *Artifact, libraries map[string]common.Address, params ...interface{}) (*Contract, *types.Transaction, error) {
+	address, tx, err := client.DeployArtifact(ctx, from, artifact, libraries, params...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	receipt, err := client.WaitMined(ctx, tx.Hash())
+	if err != nil {
+		return nil, tx, err
+	} else if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, tx, ErrDeployFailed
+	}
+
+	contract := NewContract(client, address, artifact.ABI)
+	if err := contract.WaitCode(ctx); err != nil {
+		return nil, tx, err
+	}
+	return contract, tx, nil
+}
+
+// NewArtifactContract binds the deployed contract of artifact
+func NewArtifactContract(client *Client, address common.Address, artifact *Artifact) *Contract {
+	return NewContract(client, address, artifact.ABI)
+}
+
+func (ct *Contract) Address() common.Address { return ct.address }
+func (ct *Contract) ABI() abi.ABI            { return ct.abi }
+func (ct *Contract) Client() *Client         { return ct.client }
+
+// WaitCode waits until the node of the client has the code of contract,
+// which may be behind the node the contract is deployed through
+func (ct *Contract) WaitCode(ctx context.Context) error {
+	waiter := ct.client.newHeadWaiter(ctx)
+	defer waiter.Close()
+
+	for {
+		code, err := ct.client.CodeAt(ctx, ct.address, nil)
+		if err != nil {
+			return err
+		} else if len(code) > 0 {
+			return nil
+		}
+
+		if err := waiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// CallOpts selects the block a constant method runs on, the latest block
+// is used if BlockNumber is nil and Pending is false
+type CallOpts struct {
+	From        common.Address
+	BlockNumber *big.Int
+	Pending     bool
+}
+
+// Call runs a constant method at the latest block and returns its outputs
+// in order
+func (ct *Contract) Call(ctx context.Context, method string, args ...interface{}) ([]interface{}, error) {
+	return ct.CallWithOpts(ctx, nil, method, args...)
+}
+
+func (ct *Contract) CallWithOpts(ctx context.Context, opts *CallOpts, method string, args ...interface{}) ([]interface{}, error) {
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+
+	m, ok := ct.abi.Methods[method]
+	if ok == false {
+		return nil, fmt.Errorf("%s: %s", ErrUnknownMethod.Error(), method)
+	}
+	input, err := ct.abi.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := ethereum.CallMsg{From: opts.From, To: &ct.address, Data: input}
+	var output []byte
+	if opts.Pending {
+		output, err = ct.client.PendingCallContract(ctx, msg)
+	} else {
+		output, err = ct.client.CallContract(ctx, msg, opts.BlockNumber)
+	}
+	if err != nil {
+		return nil, err
+	} else if len(output) == 0 && len(m.Outputs) > 0 {
+		return nil, ErrNoCode
+	}
+	return unpackArguments(m.Outputs, output)
+}
+
+// arguments are unpacked into new values of their go types, same as abigen
+func unpackArguments(args []abi.Argument, data []byte) ([]interface{}, error) {
+	// abi only unpacks outputs of a method by name
+	const name = "arguments"
+	argsABI := abi.ABI{Methods: map[string]abi.Method{
+		name: {Name: name, Outputs: args},
+	}}
+
+	switch len(args) {
+	case 0:
+		return nil, nil
+	case 1:
+		result := reflect.New(args[0].Type.Type)
+		if err := argsABI.Unpack(result.Interface(), name, data); err != nil {
+			return nil, err
+		}
+		return []interface{}{result.Elem().Interface()}, nil
+	}
+
+	results := make([]interface{}, len(args))
+	for i, arg := range args {
+		results[i] = reflect.New(arg.Type.Type).Interface()
+	}
+	if err := argsABI.Unpack(&results, name, data); err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		results[i] = reflect.ValueOf(result).Elem().Interface()
+	}
+	return results, nil
+}
+
+func argumentName(arg abi.Argument, index int) string {
+	if arg.Name == "" {
+		return fmt.Sprintf("arg%d", index)
+	}
+	return arg.Name
+}
+
+type MethodArgument struct {
+	Name  string
+	Value interface{}
+}
+
+// MethodCall is the input of a call decoded with the abi of its contract,
+// arguments are in abi order and unnamed ones are named argN
+type MethodCall struct {
+	Name string
+	Args []MethodArgument
+}
+
+// Value returns the argument named name
+func (c *MethodCall) Value(name string) (interface{}, bool) {
+	for _, arg := range c.Args {
+		if arg.Name == name {
+			return arg.Value, true
+		}
+	}
+	return nil, false
+}
+
+func DecodeInput(contractABI abi.ABI, input []byte) (*MethodCall, error) {
+	if len(input) < 4 {
+		return nil, fmt.Errorf("%s: input is too short", ErrUnknownMethod.Error())
+	}
+
+	for _, method := range contractABI.Methods {
+		if bytes.Equal(method.Id(), input[:4]) == false {
+			continue
+		}
+
+		values, err := unpackArguments(method.Inputs, input[4:])
+		if err != nil {
+			return nil, err
+		}
+		call := &MethodCall{
+			Name: method.Name,
+			Args: make([]MethodArgument, len(values)),
+		}
+		for i, value := range values {
+			call.Args[i] = MethodArgument{Name: argumentName(method.Inputs[i], i), Value: value}
+		}
+		return call, nil
+	}
+	return nil, fmt.Errorf("%s: selector %x", ErrUnknownMethod.Error(), input[:4])
+}
+
+// Transact sends a transaction calling method
+func (ct *Contract) Transact(from Signer, method string, args ...interface{}) (*types.Transaction, error) {
+	return ct.TransactContext(context.Background(), from, method, args...)
+}
+
+func (ct *Contract) TransactContext(ctx context.Context, from Signer, method string, args ...interface{}) (*types.Transaction, error) {
+	return ct.TransactWithOpts(ctx, from, nil, method, args...)
+}
+
+func (ct *Contract) TransactWithOpts(ctx context.Context, from Signer, opts *SendOpts, method string, args ...interface{}) (*types.Transaction, error) {
+	input, err := ct.abi.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ct.client.OnlineCallWithOpts(ctx, ct.address, from, input, opts)
+}
+
+// EventFilter returns a filter builder of the events of contract
+func (ct *Contract) EventFilter() *FilterBuilder {
+	return NewFilterBuilder(ct.abi).SetAddresses(ct.address)
+}
+
+func (ct *Contract) DecodeLog(log *types.Log) (*Event, error) {
+	return DecodeLog(ct.abi, log)
+}
+
+func (ct *Contract) DecodeInput(input []byte) (*MethodCall, error) {
+	return DecodeInput(ct.abi, input)
+}
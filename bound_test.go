@@ -0,0 +1,55 @@
+package ethclient
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testTransferABI = `[{"type":"function","name":"transfer","inputs":[
+	{"name":"to","type":"address"},
+	{"name":"amount","type":"uint256"},
+	{"name":"","type":"bytes32"},
+	{"name":"memo","type":"string"}
+],"outputs":[]}]`
+
+func TestDecodeInputOrder(t *testing.T) {
+	contractABI, err := ABIFromString(testTransferABI)
+	if err != nil {
+		t.Fatalf("abi: %v", err)
+	}
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	tag := [32]byte{1}
+	input, err := contractABI.Pack("transfer", to, big.NewInt(42), tag, "hi")
+	if err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+
+	// decode several times, a map would come out in random order
+	for i := 0; i < 10; i++ {
+		call, err := DecodeInput(contractABI, input)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if call.Name != "transfer" || len(call.Args) != 4 {
+			t.Fatalf("call mismatch: %+v", call)
+		}
+		for j, name := range []string{"to", "amount", "arg2", "memo"} {
+			if call.Args[j].Name != name {
+				t.Fatalf("argument %d mismatch: have %s, want %s", j, call.Args[j].Name, name)
+			}
+		}
+		if call.Args[0].Value != to || call.Args[1].Value.(*big.Int).Int64() != 42 ||
+			call.Args[2].Value != tag || call.Args[3].Value != "hi" {
+			t.Errorf("values mismatch: %+v", call.Args)
+		}
+	}
+
+	if value, ok := (&MethodCall{Args: []MethodArgument{{"memo", "hi"}}}).Value("memo"); !ok || value != "hi" {
+		t.Errorf("value mismatch: have %v, want hi", value)
+	}
+	if _, err := DecodeInput(contractABI, []byte{1, 2, 3, 4}); err == nil {
+		t.Error("unknown selector is decoded")
+	}
+}
@@ -1,29 +1,79 @@
 package ethclient
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math/big"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// deadline of rpc calls made without one, see SetTimeout
+const DefaultTimeout = 30 * time.Second
+
+// json rpc error code of a method the node doesn't have
+const methodNotFoundCode = -32601
+
+var (
+	ErrChainIDMismatch = errors.New("transaction is signed for another chain")
+)
+
 type Client struct {
-	c *rpc.Client
+	c       *rpc.Client
+	url     string
+	timeout time.Duration
+
+	chainID   *big.Int
+	chainIDMu sync.Mutex
+
+	gasMargin uint64
+	maxFee    *big.Int
+
+	nonces *NonceManager
 }
 
 func Dial(rawurl string) (*Client, error) {
-	c, err := rpc.Dial(rawurl)
+	return DialContext(context.Background(), rawurl)
+}
+
+func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, rawurl)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{c}, nil
+	client := &Client{
+		c:         c,
+		url:       rawurl,
+		timeout:   DefaultTimeout,
+		gasMargin: DefaultGasMargin,
+	}
+	client.nonces = NewNonceManager(client)
+	return client, nil
 }
 
 func (ec *Client) Close() {
 	ec.c.Close()
 }
 
+// redial opens a new connection to the endpoint of ec with the same timeout
+func (ec *Client) redial(ctx context.Context) (*Client, error) {
+	client, err := DialContext(ctx, ec.url)
+	if err != nil {
+		return nil, err
+	}
+	client.timeout = ec.timeout
+	return client, nil
+}
+
 func NewIPCClient(dataPath string) (*Client, error) {
 	return Dial(ipcPath(dataPath))
 }
@@ -32,6 +82,101 @@ func ipcPath(dataPath string) string {
 	return filepath.Join(dataPath, "geth.ipc")
 }
 
+// SetTimeout sets the deadline applied to every rpc call whose context
+// doesn't carry one already, zero means wait forever
+func (ec *Client) SetTimeout(timeout time.Duration) {
+	ec.timeout = timeout
+}
+
+func (ec *Client) Timeout() time.Duration {
+	return ec.timeout
+}
+
+func (ec *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || ec.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, ec.timeout)
+}
+
+func (ec *Client) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := ec.withTimeout(ctx)
+	defer cancel()
+	return ec.c.CallContext(ctx, result, method, args...)
+}
+
+func (ec *Client) subscribe(ctx context.Context, channel interface{}, args ...interface{}) (ethereum.Subscription, error) {
+	ctx, cancel := ec.withTimeout(ctx)
+	defer cancel()
+	return ec.c.EthSubscribe(ctx, channel, args...)
+}
+
+func (ec *Client) Nonces() *NonceManager {
+	return ec.nonces
+}
+
+// SetNonceManager makes the clients which send from the same accounts share
+// one nonce manager
+func (ec *Client) SetNonceManager(nm *NonceManager) {
+	ec.nonces = nm
+}
+
+// ChainID returns the chain id reported by eth_chainId, nodes which don't
+// have the method fall back to net_version. Other errors are returned, so a
+// network id isn't cached as chain id after a failed call. The result is
+// cached
+func (ec *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	ec.chainIDMu.Lock()
+	defer ec.chainIDMu.Unlock()
+
+	if ec.chainID == nil {
+		var id hexutil.Big
+		if err := ec.call(ctx, &id, "eth_chainId"); err == nil {
+			ec.chainID = (*big.Int)(&id)
+		} else if isMethodNotFound(err) == false {
+			return nil, err
+		} else {
+			var version string
+			if err := ec.call(ctx, &version, "net_version"); err != nil {
+				return nil, err
+			}
+			networkID, ok := new(big.Int).SetString(version, 10)
+			if ok == false {
+				return nil, fmt.Errorf("invalid net_version %q", version)
+			}
+			ec.chainID = networkID
+		}
+	}
+	return new(big.Int).Set(ec.chainID), nil
+}
+
+func isMethodNotFound(err error) bool {
+	rpcErr, ok := err.(rpc.Error)
+	return ok && rpcErr.ErrorCode() == methodNotFoundCode
+}
+
+func (ec *Client) Signer(ctx context.Context) (types.Signer, error) {
+	chainID, err := ec.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewEIP155Signer(chainID), nil
+}
+
+func (ec *Client) checkChainID(ctx context.Context, tx *types.Transaction) error {
+	chainID, err := ec.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if tx.Protected() == false {
+		return fmt.Errorf("%s: transaction %s isn't replay protected", ErrChainIDMismatch.Error(), tx.Hash().Hex())
+	} else if tx.ChainId().Cmp(chainID) != 0 {
+		return fmt.Errorf("%s: transaction %s has chain id %v but node has %v", ErrChainIDMismatch.Error(), tx.Hash().Hex(), tx.ChainId(), chainID)
+	}
+	return nil
+}
+
 func (c *Client) Enode() string {
 	info, err := c.NodeInfo()
 	if err != nil {
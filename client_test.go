@@ -0,0 +1,107 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestClient serves the services over http, keyed by rpc namespace, and
+// dials it
+func newTestClient(t *testing.T, services map[string]interface{}) *Client {
+	srv := rpc.NewServer()
+	for name, service := range services {
+		if err := srv.RegisterName(name, service); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+	httpSrv := httptest.NewServer(srv)
+	client, err := Dial(httpSrv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		httpSrv.Close()
+		srv.Stop()
+	})
+	return client
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	client := newTestClient(t, nil)
+	if client.Timeout() != DefaultTimeout {
+		t.Fatalf("timeout mismatch: have %v, want %v", client.Timeout(), DefaultTimeout)
+	}
+}
+
+type ChainService struct {
+	id  int64
+	err error
+}
+
+func (s *ChainService) ChainId() (*hexutil.Big, error) {
+	return (*hexutil.Big)(big.NewInt(s.id)), s.err
+}
+
+type NetService struct {
+	version string
+	calls   int
+}
+
+func (s *NetService) Version() string {
+	s.calls += 1
+	return s.version
+}
+
+func TestChainID(t *testing.T) {
+	tests := []struct {
+		eth      *ChainService
+		chainID  int64
+		fallback bool
+		err      bool
+	}{
+		{&ChainService{id: 5}, 5, false, false},
+		// node without eth_chainId
+		{nil, 7, true, false},
+		// other errors aren't answered with the network id
+		{&ChainService{err: errors.New("backend is syncing")}, 0, false, true},
+	}
+	for i, test := range tests {
+		net := &NetService{version: "7"}
+		services := map[string]interface{}{"net": net}
+		if test.eth != nil {
+			services["eth"] = test.eth
+		}
+		client := newTestClient(t, services)
+
+		chainID, err := client.ChainID(context.Background())
+		if test.err {
+			if err == nil {
+				t.Errorf("test %d: have chain id %v, want error", i, chainID)
+			}
+		} else if err != nil || chainID.Int64() != test.chainID {
+			t.Errorf("test %d: have %v %v, want %d", i, chainID, err, test.chainID)
+		}
+		if (net.calls > 0) != test.fallback {
+			t.Errorf("test %d: net_version called %d times", i, net.calls)
+		}
+	}
+
+	// a canceled call isn't cached as network id
+	net := &NetService{version: "7"}
+	client := newTestClient(t, map[string]interface{}{"eth": &ChainService{id: 5}, "net": net})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ChainID(ctx); err == nil || net.calls != 0 {
+		t.Errorf("canceled call: have %v, net_version called %d times", err, net.calls)
+	}
+	if chainID, err := client.ChainID(context.Background()); err != nil || chainID.Int64() != 5 {
+		t.Errorf("have %v %v, want 5", chainID, err)
+	}
+}
@@ -1,15 +1,137 @@
 package ethclient
 
 import (
+	"bytes"
+	"context"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 )
 
+type CliqueVote struct {
+	Signer    common.Address `json:"signer"`
+	Block     uint64         `json:"block"`
+	Address   common.Address `json:"address"`
+	Authorize bool           `json:"authorize"`
+}
+
+type CliqueTally struct {
+	Authorize bool `json:"authorize"`
+	Votes     int  `json:"votes"`
+}
+
+// CliqueSnapshot is the authorization state at one block
+type CliqueSnapshot struct {
+	Number  uint64                         `json:"number"`
+	Hash    common.Hash                    `json:"hash"`
+	Signers map[common.Address]struct{}    `json:"signers"`
+	Recents map[uint64]common.Address      `json:"recents"`
+	Votes   []*CliqueVote                  `json:"votes"`
+	Tally   map[common.Address]CliqueTally `json:"tally"`
+}
+
+func (s *CliqueSnapshot) SignerList() []common.Address {
+	signers := make([]common.Address, 0, len(s.Signers))
+	for signer := range s.Signers {
+		signers = append(signers, signer)
+	}
+	return sortAddresses(signers)
+}
+
+func sortAddresses(addresses []common.Address) []common.Address {
+	for i := 0; i < len(addresses); i++ {
+		for j := i + 1; j < len(addresses); j++ {
+			if bytes.Compare(addresses[i][:], addresses[j][:]) > 0 {
+				addresses[i], addresses[j] = addresses[j], addresses[i]
+			}
+		}
+	}
+	return addresses
+}
+
 func (ec *Client) Propose(address common.Address, auth bool) error {
-	return ec.c.Call(nil, "clique_propose", address, auth)
+	return ec.ProposeContext(context.Background(), address, auth)
+}
+
+func (ec *Client) ProposeContext(ctx context.Context, address common.Address, auth bool) error {
+	return ec.call(ctx, nil, "clique_propose", address, auth)
+}
+
+func (ec *Client) Discard(address common.Address) error {
+	return ec.DiscardContext(context.Background(), address)
+}
+
+func (ec *Client) DiscardContext(ctx context.Context, address common.Address) error {
+	return ec.call(ctx, nil, "clique_discard", address)
+}
+
+func (ec *Client) Proposals() (map[common.Address]bool, error) {
+	return ec.ProposalsContext(context.Background())
 }
 
+func (ec *Client) ProposalsContext(ctx context.Context) (map[common.Address]bool, error) {
+	var proposals map[common.Address]bool
+	if err := ec.call(ctx, &proposals, "clique_proposals"); err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// Proposes returns the current proposals, nil on error.
+//
+// Deprecated: use Proposals, which reports the error
 func (ec *Client) Proposes() map[common.Address]bool {
-	var proposes map[common.Address]bool
-	ec.c.Call(proposes, "clique_proposes")
-	return proposes
+	proposals, _ := ec.Proposals()
+	return proposals
+}
+
+// Snapshot returns the snapshot at block number, nil number means latest
+func (ec *Client) Snapshot(number *big.Int) (*CliqueSnapshot, error) {
+	return ec.SnapshotContext(context.Background(), number)
+}
+
+func (ec *Client) SnapshotContext(ctx context.Context, number *big.Int) (*CliqueSnapshot, error) {
+	var snapshot *CliqueSnapshot
+	if err := ec.call(ctx, &snapshot, "clique_getSnapshot", toBlockNumArg(number)); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (ec *Client) SnapshotAtHash(hash common.Hash) (*CliqueSnapshot, error) {
+	return ec.SnapshotAtHashContext(context.Background(), hash)
+}
+
+func (ec *Client) SnapshotAtHashContext(ctx context.Context, hash common.Hash) (*CliqueSnapshot, error) {
+	var snapshot *CliqueSnapshot
+	if err := ec.call(ctx, &snapshot, "clique_getSnapshotAtHash", hash); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// CliqueSigners returns the authorized signers at block number, nil number
+// means latest
+func (ec *Client) CliqueSigners(number *big.Int) ([]common.Address, error) {
+	return ec.CliqueSignersContext(context.Background(), number)
+}
+
+func (ec *Client) CliqueSignersContext(ctx context.Context, number *big.Int) ([]common.Address, error) {
+	var signers []common.Address
+	if err := ec.call(ctx, &signers, "clique_getSigners", toBlockNumArg(number)); err != nil {
+		return nil, err
+	}
+	return signers, nil
+}
+
+func (ec *Client) CliqueSignersAtHash(hash common.Hash) ([]common.Address, error) {
+	return ec.CliqueSignersAtHashContext(context.Background(), hash)
+}
+
+func (ec *Client) CliqueSignersAtHashContext(ctx context.Context, hash common.Hash) ([]common.Address, error) {
+	var signers []common.Address
+	if err := ec.call(ctx, &signers, "clique_getSignersAtHash", hash); err != nil {
+		return nil, err
+	}
+	return signers, nil
 }
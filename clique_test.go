@@ -0,0 +1,43 @@
+package ethclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type CliqueService struct {
+	proposals map[common.Address]bool
+}
+
+func (s *CliqueService) Proposals() map[common.Address]bool {
+	return s.proposals
+}
+
+func TestProposals(t *testing.T) {
+	want := map[common.Address]bool{
+		common.HexToAddress("0x01"): true,
+		common.HexToAddress("0x02"): false,
+	}
+	client := newTestClient(t, map[string]interface{}{"clique": &CliqueService{want}})
+
+	proposals, err := client.Proposals()
+	if err != nil {
+		t.Fatalf("proposals: %v", err)
+	}
+	if !reflect.DeepEqual(proposals, want) {
+		t.Fatalf("proposals mismatch: have %v, want %v", proposals, want)
+	}
+	if proposes := client.Proposes(); !reflect.DeepEqual(proposes, want) {
+		t.Fatalf("deprecated proposes mismatch: have %v, want %v", proposes, want)
+	}
+}
+
+func TestSortAddresses(t *testing.T) {
+	a, b, c := common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")
+	sorted := sortAddresses([]common.Address{c, a, b})
+	if !reflect.DeepEqual(sorted, []common.Address{a, b, c}) {
+		t.Fatalf("addresses not sorted: %v", sorted)
+	}
+}
@@ -1,9 +1,22 @@
 package cluster
 
+type Consensus string
+
+const (
+	Clique Consensus = "clique"
+	// signer nodes are miners in ethash mode
+	Ethash Consensus = "ethash"
+)
+
 type Config struct {
 	SignerCount  int
 	SyncerCount  int
 	NodeDataPath string
 	KeyStorePath string
 	GethPath     string
+	// accounts are generated from Seed when it isn't empty, so they are
+	// the same across runs
+	Seed string
+	// Clique is used if Consensus is empty
+	Consensus Consensus
 }
@@ -5,8 +5,10 @@ import (
 	"math/big"
 	"math/rand"
 	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"goldenteam/ethclient"
 )
@@ -19,9 +21,21 @@ type Controller struct {
 	signers        *nodeSlice
 	syncers        *nodeSlice
 	keyGenerator   *ethclient.KeyGenerator
+	nonces         *ethclient.NonceManager
+	consensus      Consensus
+	seeded         bool
+	contractABIs   map[common.Address]abi.ABI
+	abiMu          sync.RWMutex
 }
 
 func NewController(conf *Config) (*Controller, error) {
+	consensus := conf.Consensus
+	if consensus == "" {
+		consensus = Clique
+	} else if consensus != Clique && consensus != Ethash {
+		return nil, ErrUnknownConsensus
+	}
+
 	nodeManager, err := NewNodeManager(conf)
 	if err != nil {
 		return nil, err
@@ -32,17 +46,44 @@ func NewController(conf *Config) (*Controller, error) {
 		return nil, err
 	}
 
-	return &Controller{
+	keyGenerator := ethclient.NewLightKeyGenerator(keyFilePath)
+	if conf.Seed != "" {
+		if err := keyGenerator.SetSeed(conf.Seed); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &Controller{
 		nodeManager:    nodeManager,
 		signerAccounts: make(map[string]common.Address),
 		signers:        NewNodeSlice(Signer, conf.SignerCount),
 		syncers:        NewNodeSlice(Syncer, conf.SyncerCount),
-		keyGenerator:   ethclient.NewKeyGenerator(keyFilePath),
-	}, nil
+		keyGenerator:   keyGenerator,
+		consensus:      consensus,
+		seeded:         conf.Seed != "",
+		contractABIs:   make(map[common.Address]abi.ABI),
+	}
+	c.nonces = ethclient.NewNonceManager(&clusterNonceReader{c})
+	return c, nil
+}
+
+// read pending nonce from one running signer, so nonce state survives node
+// stop and restart
+type clusterNonceReader struct {
+	ctrl *Controller
+}
+
+func (r *clusterNonceReader) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	signers := r.ctrl.signers.Nodes()
+	if len(signers) == 0 {
+		return 0, ErrNoRunningNode
+	}
+	return r.ctrl.nodeManager.Client(signers[rand.Intn(len(signers))]).PendingNonceAt(ctx, account)
 }
 
-func (c *Controller) Signers() *nodeSlice { return c.signers }
-func (c *Controller) Syncers() *nodeSlice { return c.syncers }
+func (c *Controller) Consensus() Consensus { return c.consensus }
+func (c *Controller) Signers() *nodeSlice  { return c.signers }
+func (c *Controller) Syncers() *nodeSlice  { return c.syncers }
 func (c *Controller) SignerWithAccount(target common.Address) string {
 	for name, address := range c.signerAccounts {
 		if address == target {
@@ -52,6 +93,20 @@ func (c *Controller) SignerWithAccount(target common.Address) string {
 	return ""
 }
 
+// RegisterABI lets logs of the contract be decoded
+func (c *Controller) RegisterABI(address common.Address, contractABI abi.ABI) {
+	c.abiMu.Lock()
+	defer c.abiMu.Unlock()
+	c.contractABIs[address] = contractABI
+}
+
+func (c *Controller) ContractABI(address common.Address) (abi.ABI, bool) {
+	c.abiMu.RLock()
+	defer c.abiMu.RUnlock()
+	contractABI, ok := c.contractABIs[address]
+	return contractABI, ok
+}
+
 func (c *Controller) ClientForNode(n *Node) (*ethclient.Client, error) {
 	if c.signers.Include(n) == false && c.syncers.Include(n) == false {
 		return nil, ErrUnknownNode
@@ -79,12 +134,38 @@ func (c *Controller) StartAllNodes() error {
 	return nil
 }
 
+// CheckGenesis computes the genesis hash from the genesis file, and returns
+// the running nodes whose block 0 is different
+func (c *Controller) CheckGenesis() (common.Hash, []string, error) {
+	genesis, err := ethclient.LoadGenesis(c.nodeManager.GenesisPath())
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	hash, _ := ethclient.GenesisHash(genesis)
+
+	var mismatched []string
+	for _, n := range append(c.signers.Nodes(), c.syncers.Nodes()...) {
+		header, err := c.nodeManager.Client(n).HeaderByNumber(big.NewInt(0))
+		if err != nil {
+			return common.Hash{}, nil, err
+		}
+		if header.Hash() != hash {
+			mismatched = append(mismatched, n.Name())
+		}
+	}
+	return hash, mismatched, nil
+}
+
 func (c *Controller) StopNode(n *Node) error {
 	if n.Role() == Signer {
 		if err := c.signers.RemoveNode(n); err != nil {
 			return err
 		}
 
+		if c.consensus == Ethash {
+			_, err := c.nodeManager.Client(n).MinerStop()
+			return err
+		}
 		for _, otherSigner := range c.signers.Nodes() {
 			c.nodeManager.Client(otherSigner).Propose(c.signerAccounts[n.Name()], false)
 		}
@@ -106,6 +187,9 @@ func (c *Controller) RestartNode(n *Node) error {
 			return err
 		}
 
+		if c.consensus == Ethash {
+			return c.startSign(n)
+		}
 		for _, otherSigner := range otherSigners {
 			c.nodeManager.Client(otherSigner).Propose(c.signerAccounts[n.Name()], true)
 		}
@@ -145,7 +229,9 @@ func (c *Controller) AddSigner() (*Node, error) {
 		return nil, err
 	}
 
-	c.signerAccounts[signer.Name()] = accounts[0]
+	if err := c.assignSigner(signer, accounts[0]); err != nil {
+		return nil, err
+	}
 	if err := c.nodeManager.StartNode(signer); err != nil {
 		return nil, err
 	}
@@ -154,8 +240,10 @@ func (c *Controller) AddSigner() (*Node, error) {
 		return nil, err
 	}
 
-	for _, otherSigner := range runningSigners {
-		c.nodeManager.Client(otherSigner).Propose(accounts[0], true)
+	if c.consensus == Clique {
+		for _, otherSigner := range runningSigners {
+			c.nodeManager.Client(otherSigner).Propose(accounts[0], true)
+		}
 	}
 	return signer, nil
 }
@@ -169,70 +257,91 @@ func (c *Controller) generateGensisBlock() error {
 	}
 
 	testAccounts, err := c.createAccount(10)
+	if err != nil {
+		return err
+	}
 	funds := make(map[common.Address]*big.Int)
 	for _, account := range append(accounts, testAccounts...) {
 		funds[account] = big.NewInt(1000000000000000000)
 	}
 
-	genesis := ethclient.MakeGenesis(blockDuration, accounts, funds)
+	builder := ethclient.NewGenesisBuilder()
+	if c.seeded {
+		// same seed, same genesis
+		builder.SetTimestamp(time.Unix(0, 0))
+	}
+	if c.consensus == Ethash {
+		builder.SetEthash()
+	} else {
+		builder.SetPeriod(blockDuration).SetSigners(accounts)
+	}
+	for account, fund := range funds {
+		builder.Prefund(account, fund)
+	}
+	genesis := builder.Build()
 	if err := ethclient.CreateGensisFile(genesis, c.nodeManager.GenesisPath()); err != nil {
 		return err
 	}
+	c.nodeManager.SetNetworkID(genesis.Config.ChainId)
 
 	for i, n := range c.signers.Nodes() {
-		c.signerAccounts[n.Name()] = accounts[i]
+		if err := c.assignSigner(n, accounts[i]); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (c *Controller) createAccount(n int) ([]common.Address, error) {
-	var addresses []common.Address
-	for i := 0; i < n; i++ {
-		addr, err := c.keyGenerator.GenerateKey(DefaultPasswd)
-		if err != nil {
-			return nil, err
-		}
-		addresses = append(addresses, addr)
+// assignSigner puts the key file of address into the keystore of the node
+// before it starts, so the node unlocks it by itself
+func (c *Controller) assignSigner(n *Node, address common.Address) error {
+	if _, err := c.keyGenerator.CopyKeyFile(address, c.nodeManager.KeyStorePath(n)); err != nil {
+		return err
 	}
-	return addresses, nil
+	c.signerAccounts[n.Name()] = address
+	c.nodeManager.SetEtherbase(n, address)
+	return nil
 }
 
+func (c *Controller) createAccount(n int) ([]common.Address, error) {
+	return c.keyGenerator.GenerateKeys(DefaultPasswd, n)
+}
+
+// startSign starts sealing blocks, or mining in ethash mode
 func (c *Controller) startSign(signer *Node) error {
-	client := c.nodeManager.Client(signer)
-	address := c.signerAccounts[signer.Name()]
-	account, err := c.keyGenerator.GetAccount(address, DefaultPasswd)
-	if err != nil {
-		return err
-	}
-	client.SetCoinbase(account)
-	return client.MinerStart(1)
+	return c.nodeManager.Client(signer).MinerStart(1)
 }
 
 func (c *Controller) Accounts() []common.Address {
 	return c.keyGenerator.ListAddress()
 }
 
-func (c *Controller) TransferMoney(from, to common.Address, value int64, count int) error {
-	runningSigners := c.Signers().Nodes()
+func (c *Controller) TransferMoney(from, to common.Address, value int64, count int) ([]common.Hash, error) {
 	runningSyncers := c.Syncers().Nodes()
+	if len(runningSyncers) == 0 {
+		return nil, ErrNoRunningNode
+	}
 	syncerName := runningSyncers[rand.Intn(len(runningSyncers))]
-	signerName := runningSigners[rand.Intn(len(runningSigners))]
 
 	fromAccount, err := c.keyGenerator.GetAccount(from, DefaultPasswd)
 	if err != nil {
-		return err
-	}
-	nonce, err := c.nodeManager.Client(signerName).PendingNonceAt(context.Background(), fromAccount.Address())
-	if err != nil {
-		return err
+		return nil, err
 	}
 
+	ctx := context.Background()
+	var hashes []common.Hash
 	for i := 0; i < count; i++ {
-		err = c.nodeManager.Client(syncerName).Transfer(fromAccount, to, nonce, big.NewInt(value))
+		nonce, err := c.nonces.Next(ctx, from)
 		if err != nil {
-			return err
+			return hashes, err
 		}
-		nonce += 1
+
+		tx, err := c.nodeManager.Client(syncerName).TransferContext(ctx, fromAccount, to, nonce, big.NewInt(value))
+		if err != nil {
+			c.nonces.Failed(from, nonce, err)
+			return hashes, err
+		}
+		hashes = append(hashes, tx.Hash())
 	}
-	return nil
+	return hashes, nil
 }
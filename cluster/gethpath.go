@@ -31,6 +31,14 @@ func (gp *GethPath) NodeDataPath(n *Node) string {
 	return filepath.Join(gp.datapath, n.Name())
 }
 
+func (gp *GethPath) NodeKeyStorePath(n *Node) string {
+	return filepath.Join(gp.NodeDataPath(n), "keystore")
+}
+
+func (gp *GethPath) NodePasswordPath(n *Node) string {
+	return filepath.Join(gp.datapath, fmt.Sprintf("%s.password", n.Name()))
+}
+
 func (gp *GethPath) NodeLogPath(n *Node) string {
 	return filepath.Join(gp.datapath, fmt.Sprintf("%s.log", n.Name()))
 }
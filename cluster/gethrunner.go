@@ -1,13 +1,21 @@
 package cluster
 
 import (
+	"io/ioutil"
+	"math/big"
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
+const defaultNetworkID = "77877"
+
 type gethRunner struct {
 	gethpath    *GethPath
+	networkID   string
 	nextp2pport int
 	nextrpcport int
 	portMu      sync.Mutex
@@ -16,14 +24,19 @@ type gethRunner struct {
 func NewGethRunner(gethpath *GethPath) *gethRunner {
 	return &gethRunner{
 		gethpath:    gethpath,
+		networkID:   defaultNetworkID,
 		nextp2pport: 8800,
 		nextrpcport: 9900,
 	}
 }
 
-func (r *gethRunner) StartGeth(n *Node) (*os.Process, error) {
+// etherbase isn't nil for signer, its key file should be in the keystore of
+// the node already, it's unlocked with a password file, so neither the key
+// nor the password is sent by rpc
+func (r *gethRunner) StartGeth(n *Node, etherbase *common.Address) (*os.Process, error) {
 	nodedatapath := r.gethpath.NodeDataPath(n)
-	if PathExists(nodedatapath) == false {
+	// keystore may be created before the chain data
+	if PathExists(filepath.Join(nodedatapath, "geth")) == false {
 		if err := os.MkdirAll(nodedatapath, 0700); err != nil {
 			return nil, err
 		}
@@ -34,13 +47,33 @@ func (r *gethRunner) StartGeth(n *Node) (*os.Process, error) {
 	}
 
 	p2pport, rpcport := r.allocatePort()
-	return startProcess(r.gethpath.GethPath(), r.gethpath.NodeLogPath(n),
+	args := []string{
 		"--datadir", nodedatapath,
-		"--networkid", "77877",
+		"--networkid", r.networkID,
 		"--nodiscover",
 		"--rpc",
 		"--rpcport", strconv.Itoa(rpcport),
-		"--port", strconv.Itoa(p2pport))
+		"--port", strconv.Itoa(p2pport),
+	}
+
+	if etherbase != nil {
+		passwordPath := r.gethpath.NodePasswordPath(n)
+		if err := ioutil.WriteFile(passwordPath, []byte(DefaultPasswd), 0600); err != nil {
+			return nil, err
+		}
+		args = append(args,
+			"--unlock", etherbase.Hex(),
+			"--password", passwordPath,
+			"--etherbase", etherbase.Hex())
+	}
+
+	return startProcess(r.gethpath.GethPath(), r.gethpath.NodeLogPath(n), args...)
+}
+
+// network id should equal to the chain id, so clients which fall back to
+// net_version sign transactions for the right chain
+func (r *gethRunner) SetNetworkID(id *big.Int) {
+	r.networkID = id.String()
 }
 
 func (r *gethRunner) initGenesis(nodedatapath string) error {
@@ -17,6 +17,8 @@ var (
 	ErrRoleUnMatch        = errors.New("node isn't belongs to current slice it has different role")
 	ErrNodeAlreadyExists  = errors.New("node already exists")
 	ErrTooManyNode        = errors.New("too many nodes")
+	ErrNoRunningNode      = errors.New("no running node")
+	ErrUnknownConsensus   = errors.New("consensus should be clique or ethash")
 )
 
 type Role string
@@ -75,7 +77,6 @@ func (n *Node) Client(path *GethPath) *ethclient.Client {
 		}
 		<-time.After(time.Second)
 	}
-	return nil
 }
 
 const maxNodeCount = 100
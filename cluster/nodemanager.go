@@ -2,18 +2,21 @@ package cluster
 
 import (
 	"log"
+	"math/big"
 	"os"
 	"sync"
 
+	"github.com/ethereum/go-ethereum/common"
 	"goldenteam/ethclient"
 )
 
 type NodeManager struct {
-	clients   map[string]*ethclient.Client
-	processes map[string]*os.Process
-	mu        sync.Mutex
-	runner    *gethRunner
-	gethpath  *GethPath
+	clients    map[string]*ethclient.Client
+	processes  map[string]*os.Process
+	etherbases map[string]common.Address
+	mu         sync.Mutex
+	runner     *gethRunner
+	gethpath   *GethPath
 }
 
 func NewNodeManager(conf *Config) (*NodeManager, error) {
@@ -23,10 +26,11 @@ func NewNodeManager(conf *Config) (*NodeManager, error) {
 	}
 
 	return &NodeManager{
-		clients:   make(map[string]*ethclient.Client),
-		processes: make(map[string]*os.Process),
-		runner:    NewGethRunner(gethpath),
-		gethpath:  gethpath,
+		clients:    make(map[string]*ethclient.Client),
+		processes:  make(map[string]*os.Process),
+		etherbases: make(map[string]common.Address),
+		runner:     NewGethRunner(gethpath),
+		gethpath:   gethpath,
 	}, nil
 }
 
@@ -61,7 +65,16 @@ func (nm *NodeManager) StartNode(n *Node) error {
 	}
 
 	log.Printf("start to launch node %s\n", n.Name())
-	p, err := nm.runner.StartGeth(n)
+	nm.mu.Lock()
+	etherbase, ok := nm.etherbases[n.Name()]
+	nm.mu.Unlock()
+	var p *os.Process
+	var err error
+	if ok {
+		p, err = nm.runner.StartGeth(n, &etherbase)
+	} else {
+		p, err = nm.runner.StartGeth(n, nil)
+	}
 	if err != nil {
 		return err
 	}
@@ -105,6 +118,22 @@ func (nm *NodeManager) StopNode(n *Node) error {
 	return nil
 }
 
+// SetEtherbase makes the node start with address unlocked as etherbase, the
+// key file of address should be put into KeyStorePath of the node first
+func (nm *NodeManager) SetEtherbase(n *Node, address common.Address) {
+	nm.mu.Lock()
+	nm.etherbases[n.Name()] = address
+	nm.mu.Unlock()
+}
+
+func (nm *NodeManager) KeyStorePath(n *Node) string {
+	return nm.gethpath.NodeKeyStorePath(n)
+}
+
+func (nm *NodeManager) SetNetworkID(id *big.Int) {
+	nm.runner.SetNetworkID(id)
+}
+
 func (nm *NodeManager) GenesisPath() string {
 	return nm.gethpath.GenesisPath()
 }
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"os"
@@ -8,9 +9,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/sha3"
 	"github.com/ethereum/go-ethereum/rlp"
+	"goldenteam/ethclient"
 )
 
 const (
@@ -20,13 +21,7 @@ const (
 
 func Ecrecover(header *types.Header) (common.Address, error) {
 	signature := header.Extra[len(header.Extra)-extraSeal:]
-	pubkey, err := crypto.Ecrecover(sigHash(header).Bytes(), signature)
-	if err != nil {
-		return common.Address{}, err
-	}
-	var signer common.Address
-	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
-	return signer, nil
+	return ethclient.Ecrecover(sigHash(header).Bytes(), signature)
 }
 
 func sigHash(header *types.Header) (hash common.Hash) {
@@ -69,43 +64,76 @@ func headerInfo(b *types.Block) (voteAddr string, isVote bool, signers []string,
 	return
 }
 
+// BlockMarshaling has the clique signer fields, or the ethash mining fields
+// when the cluster runs ethash. The fields of the other engine are left out
 type BlockMarshaling struct {
-	Signer       string    `json:"signer"`
 	Number       uint64    `json:"number"`
 	Hash         string    `json:"hash"`
 	Parent       string    `json:"parent"`
 	Transactions []string  `json:"transactions"`
 	Difficulty   uint64    `json:"difficulty"`
 	Time         time.Time `json:"time"`
-	IsVote       bool      `json:"is_vote"`
-	VoteAddress  string    `json:"vote_address"`
-	IsEpoch      bool      `json:"is_epoch"`
-	Signers      []string  `json:"signers"`
+
+	*CliqueMarshaling
+	*EthashMarshaling
+}
+
+type CliqueMarshaling struct {
+	Signer      string   `json:"signer"`
+	IsVote      bool     `json:"is_vote"`
+	VoteAddress string   `json:"vote_address"`
+	IsEpoch     bool     `json:"is_epoch"`
+	Signers     []string `json:"signers"`
+}
+
+type EthashMarshaling struct {
+	Miner     string   `json:"miner"`
+	Nonce     uint64   `json:"nonce"`
+	MixDigest string   `json:"mix_digest"`
+	Uncles    []string `json:"uncles"`
 }
 
 func BlockInPOA(block *types.Block, ctrl *Controller) BlockMarshaling {
 	h := block.Header()
-	signerAddr, _ := Ecrecover(h)
 
 	var txs []string
 	for _, tx := range block.Body().Transactions {
 		txs = append(txs, tx.Hash().Hex())
 	}
 
-	voteAddr, isVote, signers, isEpoch := headerInfo(block)
-	return BlockMarshaling{
-		Signer:       ctrl.SignerWithAccount(signerAddr),
+	bm := BlockMarshaling{
 		Number:       h.Number.Uint64(),
 		Hash:         h.Hash().Hex(),
 		Parent:       h.ParentHash.Hex(),
 		Transactions: txs,
 		Difficulty:   h.Difficulty.Uint64(),
 		Time:         time.Unix(h.Time.Int64(), 0),
-		IsVote:       isVote,
-		VoteAddress:  voteAddr,
-		IsEpoch:      isEpoch,
-		Signers:      signers,
 	}
+
+	if ctrl.Consensus() == Ethash {
+		em := &EthashMarshaling{
+			Miner:     ctrl.SignerWithAccount(h.Coinbase),
+			Nonce:     h.Nonce.Uint64(),
+			MixDigest: h.MixDigest.Hex(),
+			Uncles:    []string{},
+		}
+		if em.Miner == "" {
+			em.Miner = h.Coinbase.Hex()
+		}
+		for _, uncle := range block.Uncles() {
+			em.Uncles = append(em.Uncles, uncle.Hash().Hex())
+		}
+		bm.EthashMarshaling = em
+		return bm
+	}
+
+	signerAddr, _ := Ecrecover(h)
+	cm := &CliqueMarshaling{
+		Signer: ctrl.SignerWithAccount(signerAddr),
+	}
+	cm.VoteAddress, cm.IsVote, cm.Signers, cm.IsEpoch = headerInfo(block)
+	bm.CliqueMarshaling = cm
+	return bm
 }
 
 type TransactionMarshaling struct {
@@ -140,31 +168,72 @@ func TransactionInPOA(tx *types.Transaction) TransactionMarshaling {
 }
 
 type ReceiptMarshaling struct {
-	Succeed bool            `json:"succeed"`
-	Logs    []LogMarshaling `json:"logs"`
+	Succeed      bool            `json:"succeed"`
+	RevertReason string          `json:"revert_reason,omitempty"`
+	Logs         []LogMarshaling `json:"logs"`
 }
 
-func ReceiptInPOA(r *types.Receipt) ReceiptMarshaling {
+// ReceiptInPOA looks up the revert reason of a failed transaction through
+// client, which replays it with eth_call at the block before it
+func ReceiptInPOA(client *ethclient.Client, r *types.Receipt, ctrl *Controller) ReceiptMarshaling {
 	var logs []LogMarshaling
 	for _, log := range r.Logs {
-		logs = append(logs, LogInPOA(log))
+		logs = append(logs, LogInPOA(log, ctrl))
 	}
-	return ReceiptMarshaling{
+
+	rm := ReceiptMarshaling{
 		Succeed: r.Status == types.ReceiptStatusSuccessful,
 		Logs:    logs,
 	}
+	if rm.Succeed == false {
+		reason, err := client.TransactionRevertReason(context.Background(), r.TxHash)
+		if err != nil {
+			rm.RevertReason = "unknown: " + err.Error()
+		} else if reason == "" {
+			rm.RevertReason = "reverted without reason"
+		} else {
+			rm.RevertReason = reason
+		}
+	}
+	return rm
 }
 
+// LogMarshaling has the event and its values if the abi of the contract is
+// registered to controller
 type LogMarshaling struct {
-	Topics string `json:"topics"`
-	Data   string `json:"data"`
+	Address string            `json:"address"`
+	Topics  string            `json:"topics"`
+	Data    string            `json:"data"`
+	Event   string            `json:"event,omitempty"`
+	Values  map[string]string `json:"values,omitempty"`
 }
 
-func LogInPOA(l *types.Log) LogMarshaling {
-	return LogMarshaling{
-		Topics: fmt.Sprintf("%x", l.Topics),
-		Data:   fmt.Sprintf("%x", l.Data),
+func LogInPOA(l *types.Log, ctrl *Controller) LogMarshaling {
+	lm := LogMarshaling{
+		Address: l.Address.Hex(),
+		Topics:  fmt.Sprintf("%x", l.Topics),
+		Data:    fmt.Sprintf("%x", l.Data),
+	}
+
+	contractABI, ok := ctrl.ContractABI(l.Address)
+	if ok == false {
+		return lm
+	}
+	event, err := ethclient.DecodeLog(contractABI, l)
+	if err != nil {
+		return lm
+	}
+
+	lm.Event = event.Name
+	lm.Values = make(map[string]string)
+	for name, value := range event.Values {
+		if b, ok := value.([]byte); ok {
+			lm.Values[name] = fmt.Sprintf("0x%x", b)
+		} else {
+			lm.Values[name] = fmt.Sprintf("%v", value)
+		}
 	}
+	return lm
 }
 
 func PathExists(path string) bool {
@@ -13,6 +13,8 @@ var (
 	signerCount  int
 	syncerCount  int
 	gethPath     string
+	seed         string
+	consensus    string
 )
 
 func init() {
@@ -21,6 +23,8 @@ func init() {
 	flag.StringVar(&gethPath, "p", "", "geth cmd path")
 	flag.IntVar(&signerCount, "s", 2, "signer node count")
 	flag.IntVar(&syncerCount, "y", 3, "syncer node count")
+	flag.StringVar(&seed, "seed", "", "generate the same accounts from seed")
+	flag.StringVar(&consensus, "c", "clique", "consensus engine, clique or ethash")
 }
 
 func main() {
@@ -31,6 +35,8 @@ func main() {
 		SignerCount:  signerCount,
 		SyncerCount:  syncerCount,
 		GethPath:     gethPath,
+		Seed:         seed,
+		Consensus:    cluster.Consensus(consensus),
 	})
 	if err != nil {
 		panic("create ctrl failed:" + err.Error())
@@ -40,10 +46,12 @@ func main() {
 		panic("start nodes failed:" + err.Error())
 	}
 
+	executor, livePrefix := NewExecutor(ctrl)
 	p := prompt.New(
-		NewExecutor(ctrl),
+		executor,
 		NewCompleter(ctrl),
 		prompt.OptionPrefix(">>> "),
+		prompt.OptionLivePrefix(livePrefix),
 		prompt.OptionTitle("ethctrl-prompt"),
 	)
 	p.Run()
@@ -33,6 +33,9 @@ var commands = []prompt.Suggest{
 	{Text: "balance", Description: "Get balance of one account"},
 	{Text: "transaction", Description: "Get transaction with hash"},
 	{Text: "transfer", Description: "Transfer money between random accounts"},
+	{Text: "trace", Description: "show call tree of transaction with hash"},
+	{Text: "abi", Description: "register abi of a contract to decode its logs and calls"},
+	{Text: "genesis", Description: "check all nodes have the same genesis"},
 	{Text: "quit", Description: "quite the app"},
 }
 
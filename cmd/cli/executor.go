@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"math/rand"
 	"os"
@@ -19,9 +20,17 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-func NewExecutor(ctrl *cluster.Controller) func(*prompt.Prompt, string) {
+// NewExecutor returns the executor and the live prefix of the prompt, which
+// names the selected node
+func NewExecutor(ctrl *cluster.Controller) (prompt.Executor, func() (string, bool)) {
 	var currentNode *cluster.Node
-	return func(p *prompt.Prompt, in string) {
+	livePrefix := func() (string, bool) {
+		if currentNode == nil {
+			return ">>> ", true
+		}
+		return currentNode.Name() + ">>> ", true
+	}
+	return func(in string) {
 		in = strings.TrimSpace(in)
 		if in == "" {
 			return
@@ -102,20 +111,27 @@ func NewExecutor(ctrl *cluster.Controller) func(*prompt.Prompt, string) {
 				count, err = strconv.Atoi(cmdAndArgs[2])
 			}
 			cmdTransfer(ctrl, int64(value), count)
+		case "trace":
+			if len(cmdAndArgs) != 2 {
+				fmt.Printf("trace hash\n")
+				return
+			}
+			cmdTrace(ctrl, currentNode, cmdAndArgs[1])
+		case "abi":
+			if len(cmdAndArgs) != 3 {
+				fmt.Printf("abi address abifile\n")
+				return
+			}
+			cmdRegisterABI(ctrl, cmdAndArgs[1], cmdAndArgs[2])
+		case "genesis":
+			cmdGenesis(ctrl)
 		case "quit":
 			fmt.Println("Bye!")
 			os.Exit(0)
 		default:
 			fmt.Printf("unknown cmd %s\n", cmd)
 		}
-
-		if currentNode == nil {
-			p.SetPrefix(">>> ")
-		} else {
-			p.SetPrefix(currentNode.Name() + ">>> ")
-		}
-
-	}
+	}, livePrefix
 }
 
 func cmdListNodes(ctrl *cluster.Controller) {
@@ -205,27 +221,133 @@ func cmdBlock(ctrl *cluster.Controller, node *cluster.Node, number int) {
 	} else {
 		blockMarshal := cluster.BlockInPOA(block, ctrl)
 		fmt.Printf(`block:         %v
-signer:        %s
 hash:          %s
 parent:        %s
 transactions:  %v
 difficulty:    %v
 time:          %v
-is_vote:       %v
-voite_address: %v
-is_epoch:      %v
-signers:       %v
 `, blockMarshal.Number,
-			blockMarshal.Signer,
 			blockMarshal.Hash,
 			blockMarshal.Parent,
 			blockMarshal.Transactions,
 			blockMarshal.Difficulty,
-			blockMarshal.Time,
-			blockMarshal.IsVote,
-			blockMarshal.VoteAddress,
-			blockMarshal.IsEpoch,
-			blockMarshal.Signers)
+			blockMarshal.Time)
+
+		if ctrl.Consensus() == cluster.Ethash {
+			fmt.Printf(`miner:         %s
+nonce:         %v
+mix_digest:    %s
+uncles:        %v
+`, blockMarshal.Miner,
+				blockMarshal.Nonce,
+				blockMarshal.MixDigest,
+				blockMarshal.Uncles)
+		} else {
+			fmt.Printf(`signer:        %s
+is_vote:       %v
+voite_address: %v
+is_epoch:      %v
+signers:       %v
+`, blockMarshal.Signer,
+				blockMarshal.IsVote,
+				blockMarshal.VoteAddress,
+				blockMarshal.IsEpoch,
+				blockMarshal.Signers)
+		}
+	}
+}
+
+func cmdTrace(ctrl *cluster.Controller, node *cluster.Node, hash string) {
+	client := getClient(ctrl, node)
+	if client == nil {
+		return
+	}
+
+	frame, err := client.TraceTransactionCalls(common.HexToHash(hash))
+	if err != nil {
+		fmt.Printf("err:%s\n", err.Error())
+		return
+	}
+	printCallFrame(ctrl, frame, 0)
+}
+
+func printCallFrame(ctrl *cluster.Controller, frame *ethclient.CallFrame, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Printf("%s%s %s -> %s %s gas:%d used:%d",
+		indent,
+		frame.Type,
+		frame.From.Hex(),
+		frame.To.Hex(),
+		describeCall(ctrl, frame),
+		uint64(frame.Gas),
+		uint64(frame.GasUsed))
+	if frame.Value != nil && frame.Value.ToInt().Sign() > 0 {
+		fmt.Printf(" value:%v", frame.Value.ToInt())
+	}
+	fmt.Printf("\n")
+
+	if frame.Failed() {
+		if reason := frame.Reason(); reason != "" {
+			fmt.Printf("%s  error: %s, reason: %s\n", indent, frame.Error, reason)
+		} else {
+			fmt.Printf("%s  error: %s\n", indent, frame.Error)
+		}
+	}
+
+	for i := range frame.Calls {
+		printCallFrame(ctrl, &frame.Calls[i], depth+1)
+	}
+}
+
+// method and arguments if abi of the contract is registered, otherwise the
+// selector
+func describeCall(ctrl *cluster.Controller, frame *ethclient.CallFrame) string {
+	if strings.HasPrefix(frame.Type, "CREATE") || len(frame.Input) < 4 {
+		return ""
+	}
+
+	selector := fmt.Sprintf("0x%x", []byte(frame.Input[:4]))
+	contractABI, ok := ctrl.ContractABI(frame.To)
+	if ok == false {
+		return selector
+	}
+	call, err := ethclient.DecodeInput(contractABI, frame.Input)
+	if err != nil {
+		return selector
+	}
+
+	args := make([]string, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = fmt.Sprintf("%s=%v", arg.Name, arg.Value)
+	}
+	return call.Name + "(" + strings.Join(args, ",") + ")"
+}
+
+func cmdRegisterABI(ctrl *cluster.Controller, address, abiFile string) {
+	api, err := ioutil.ReadFile(abiFile)
+	if err != nil {
+		fmt.Printf("err:%s\n", err.Error())
+		return
+	}
+
+	contractABI, err := ethclient.ABIFromString(string(api))
+	if err != nil {
+		fmt.Printf("abi isn't valid:%s\n", err.Error())
+		return
+	}
+	ctrl.RegisterABI(common.HexToAddress(address), contractABI)
+}
+
+func cmdGenesis(ctrl *cluster.Controller) {
+	hash, mismatched, err := ctrl.CheckGenesis()
+	if err != nil {
+		fmt.Printf("err:%s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("genesis:%s\n", hash.Hex())
+	for _, name := range mismatched {
+		fmt.Printf("%s has different genesis\n", name)
 	}
 }
 
@@ -270,11 +392,18 @@ data:      %x
 	if err != nil {
 		fmt.Printf("err:%s\n", err.Error())
 	} else {
-		receiptMarshal := cluster.ReceiptInPOA(r)
-		fmt.Printf(`succeed:   %v
-logs:      %v
-`, receiptMarshal.Succeed,
-			receiptMarshal.Logs)
+		receiptMarshal := cluster.ReceiptInPOA(client, r, ctrl)
+		fmt.Printf("succeed:   %v\n", receiptMarshal.Succeed)
+		if receiptMarshal.RevertReason != "" {
+			fmt.Printf("revert:    %s\n", receiptMarshal.RevertReason)
+		}
+		for _, log := range receiptMarshal.Logs {
+			if log.Event != "" {
+				fmt.Printf("log:       %s %s %v\n", log.Address, log.Event, log.Values)
+			} else {
+				fmt.Printf("log:       %s topics:%s data:%s\n", log.Address, log.Topics, log.Data)
+			}
+		}
 	}
 }
 
@@ -288,10 +417,13 @@ func cmdTransfer(ctrl *cluster.Controller, value int64, count int) {
 	from := accounts[fromIndex]
 	to := accounts[toIndex]
 
-	if err := ctrl.TransferMoney(from, to, value, count); err != nil {
+	if hashes, err := ctrl.TransferMoney(from, to, value, count); err != nil {
 		fmt.Printf("%s\n", err.Error())
 	} else {
 		fmt.Printf("done transfer from %s to %s\n", from.Hex(), to.Hex())
+		for _, hash := range hashes {
+			fmt.Printf("tx: %s\n", hash.Hex())
+		}
 	}
 }
 
@@ -9,8 +9,8 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"goldenteam/ethclient"
 	"goldenteam/ethclient/cluster"
 )
@@ -27,10 +27,10 @@ type ContractClient struct {
 	account         *ethclient.Account
 	client          *ethclient.Client
 	gethpath        *cluster.GethPath
-	contractABI     abi.ABI
+	artifact        *ethclient.Artifact
 }
 
-func newContractClient(conf *cluster.Config, contract string) (*ContractClient, error) {
+func newContractClient(conf *cluster.Config, contract, artifactFile string) (*ContractClient, error) {
 	keyFilePath, err := filepath.Abs(conf.KeyStorePath)
 	if err != nil {
 		return nil, err
@@ -53,11 +53,15 @@ func newContractClient(conf *cluster.Config, contract string) (*ContractClient,
 		return nil, err
 	}
 
-	contractABI, _ := ethclient.ABIFromString(IncrementerABI)
+	artifact, err := loadArtifact(artifactFile)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &ContractClient{
-		account:     account,
-		gethpath:    gethpath,
-		contractABI: contractABI,
+		account:  account,
+		gethpath: gethpath,
+		artifact: artifact,
 	}
 
 	c.SelectNode("signer1")
@@ -65,21 +69,22 @@ func newContractClient(conf *cluster.Config, contract string) (*ContractClient,
 		c.deployContract()
 	} else {
 		c.contractAddress = common.HexToAddress(contract)
+		c.waitForContractReady()
 	}
-
-	c.waitForContractReady()
 	return c, nil
 }
 
 func (c *ContractClient) deployContract() {
-	address, err := c.client.Deploy(c.account, IncrementerABI, IncrementerBin)
+	fmt.Printf("wait for contract ready\n")
+	contract, tx, err := ethclient.DeployArtifactContract(context.Background(), c.client, c.account, c.artifact, nil)
 	if err != nil {
 		fmt.Printf("deploy contract failed %s\n", err.Error())
 		os.Exit(1)
-	} else {
-		c.contractAddress = address
-		fmt.Printf("deploy contract with address: %s\n", address.Hex())
 	}
+
+	c.contractAddress = contract.Address()
+	fmt.Printf("deploy contract with address: %s tx: %s\n", c.contractAddress.Hex(), tx.Hash().Hex())
+	fmt.Printf("contract is ready\n")
 }
 
 func (c *ContractClient) SelectNode(name string) error {
@@ -105,31 +110,39 @@ func (c *ContractClient) CurrentNodeName() string {
 	}
 }
 
+// the contract is bound to the client of current node
+func (c *ContractClient) contract() *ethclient.Contract {
+	return ethclient.NewArtifactContract(c.client, c.contractAddress, c.artifact)
+}
+
+func loadArtifact(artifactFile string) (*ethclient.Artifact, error) {
+	if artifactFile == "" {
+		return ethclient.NewArtifact("Incrementer", IncrementerABI, IncrementerBin)
+	}
+	return ethclient.LoadArtifact(artifactFile)
+}
+
 func (c *ContractClient) waitForContractReady() {
 	fmt.Printf("wait for contract ready\n")
 	for {
-		code, err := c.client.CodeAt(context.Background(), c.contractAddress, nil)
-		if err == nil && len(code) > 0 {
+		err := c.contract().WaitCode(context.Background())
+		if err == nil {
 			break
 		}
+		fmt.Printf("wait for contract failed %s\n", err.Error())
 		<-time.After(5 * time.Second)
 	}
 	fmt.Printf("contract is ready\n")
 }
 
-func (c *ContractClient) Increment() error {
-	input, _ := ethclient.PackFunctionCall(c.contractABI, "increment")
-	return c.client.OnlineCall(c.contractAddress, c.account, input)
+func (c *ContractClient) Increment() (*types.Transaction, error) {
+	return c.contract().Transact(c.account, "increment")
 }
 
 func (c *ContractClient) GetNumber() (*big.Int, error) {
-	input, _ := ethclient.PackFunctionCall(c.contractABI, "getNumber")
-	output, err := c.client.LocalCall(c.contractAddress, c.account, input)
+	out, err := c.contract().Call(context.Background(), "getNumber")
 	if err != nil {
 		return nil, err
-	} else {
-		number := big.NewInt(0)
-		err := c.contractABI.Unpack(&number, "getNumber", output)
-		return number, err
 	}
+	return out[0].(*big.Int), nil
 }
@@ -8,8 +8,8 @@ import (
 	"github.com/c-bata/go-prompt"
 )
 
-func NewExecutor(c *ContractClient) func(*prompt.Prompt, string) {
-	return func(p *prompt.Prompt, in string) {
+func NewExecutor(c *ContractClient) prompt.Executor {
+	return func(in string) {
 		in = strings.TrimSpace(in)
 		if in == "" {
 			return
@@ -34,16 +34,14 @@ func NewExecutor(c *ContractClient) func(*prompt.Prompt, string) {
 		default:
 			fmt.Printf("unknown cmd %s\n", cmd)
 		}
-
-		p.SetPrefix(c.CurrentNodeName() + ">>> ")
 	}
 }
 
 func cmdIncrement(c *ContractClient) {
-	if err := c.Increment(); err != nil {
+	if tx, err := c.Increment(); err != nil {
 		fmt.Printf("increment call failed:%s", err.Error())
 	} else {
-		fmt.Printf("ok\n")
+		fmt.Printf("ok tx:%s\n", tx.Hash().Hex())
 	}
 }
 
@@ -12,12 +12,14 @@ var (
 	nodeDataPath    string
 	keyStore        string
 	contractAddress string
+	artifactFile    string
 )
 
 func init() {
 	flag.StringVar(&nodeDataPath, "n", "", "top folder for all nodes")
 	flag.StringVar(&keyStore, "k", "", "folder to store all the key files")
 	flag.StringVar(&contractAddress, "c", "", "contract address which has been deployed")
+	flag.StringVar(&artifactFile, "a", "", "truffle or hardhat artifact of the contract, abigen code is used if empty")
 }
 
 func main() {
@@ -26,7 +28,7 @@ func main() {
 	client, err := newContractClient(&cluster.Config{
 		NodeDataPath: nodeDataPath,
 		KeyStorePath: keyStore,
-	}, contractAddress)
+	}, contractAddress, artifactFile)
 
 	if err != nil {
 		log.Fatalf("connect to fullnode failed:%s", err.Error())
@@ -36,6 +38,9 @@ func main() {
 		NewExecutor(client),
 		NewCompleter(client),
 		prompt.OptionPrefix(client.CurrentNodeName()+">>> "),
+		prompt.OptionLivePrefix(func() (string, bool) {
+			return client.CurrentNodeName() + ">>> ", true
+		}),
 		prompt.OptionTitle("contract-tester"),
 	)
 	p.Run()
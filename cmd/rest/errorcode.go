@@ -8,4 +8,6 @@ const (
 	ErrGetBlockFailed
 	ErrGetTransactionFailed
 	ErrInvalidParameter
+	ErrTransferFailed
+	ErrGetReceiptFailed
 )
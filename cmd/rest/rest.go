@@ -12,6 +12,8 @@ var (
 	signerCount  int
 	syncerCount  int
 	gethPath     string
+	seed         string
+	consensus    string
 	serverAddr   string
 )
 
@@ -21,6 +23,8 @@ func init() {
 	flag.StringVar(&gethPath, "p", "", "geth cmd path")
 	flag.IntVar(&signerCount, "s", 2, "signer node count")
 	flag.IntVar(&syncerCount, "y", 3, "syncer node count")
+	flag.StringVar(&seed, "seed", "", "generate the same accounts from seed")
+	flag.StringVar(&consensus, "c", "clique", "consensus engine, clique or ethash")
 	flag.StringVar(&serverAddr, "i", "127.0.0.1:6666", "rest server address")
 }
 
@@ -32,6 +36,8 @@ func main() {
 		SignerCount:  signerCount,
 		SyncerCount:  syncerCount,
 		GethPath:     gethPath,
+		Seed:         seed,
+		Consensus:    cluster.Consensus(consensus),
 	})
 	if err != nil {
 		panic("create ctrl failed:" + err.Error())
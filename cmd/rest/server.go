@@ -8,8 +8,8 @@ import (
 	"strconv"
 	"time"
 
-	"cement/httprouter"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
 	"goldenteam/ethclient"
 	"goldenteam/ethclient/cluster"
 )
@@ -39,6 +39,7 @@ func (s *Server) Run(addr string) {
 
 	router.GET("/api/v1/nodes/:node/blocks/:number", s.getBlock)
 	router.GET("/api/v1/nodes/:node/transactions/:hash", s.getTransaction)
+	router.GET("/api/v1/nodes/:node/receipts/:hash", s.getReceipt)
 	router.POST("/api/v1/transactions", s.transferMoney)
 	router.GET("/api/v1/accounts", s.getAccounts)
 
@@ -147,6 +148,21 @@ func (s *Server) getTransaction(w http.ResponseWriter, r *http.Request, ps httpr
 	}
 }
 
+func (s *Server) getReceipt(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	node, err := s.connectToNode(ps.ByName("node"))
+	if err != nil {
+		EncodeResult(w, Failed(ErrUnknownNode))
+		return
+	}
+
+	receipt, err := node.TransactionReceipt(common.HexToHash(ps.ByName("hash")))
+	if err != nil {
+		EncodeResult(w, Failed(ErrGetReceiptFailed))
+	} else {
+		EncodeResult(w, SucceedWithResult(cluster.ReceiptInPOA(node, receipt, s.ctrl)))
+	}
+}
+
 func (s *Server) transferMoney(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	param := struct {
 		From  string `json:"from"`
@@ -162,8 +178,19 @@ func (s *Server) transferMoney(w http.ResponseWriter, r *http.Request, ps httpro
 
 	fromAccount := common.HexToAddress(param.From)
 	toAccount := common.HexToAddress(param.To)
-	s.ctrl.TransferMoney(fromAccount, toAccount, param.Value, param.Count)
-	EncodeResult(w, Succeed())
+	hashes, err := s.ctrl.TransferMoney(fromAccount, toAccount, param.Value, param.Count)
+	if err != nil {
+		EncodeResult(w, Failed(ErrTransferFailed))
+		return
+	}
+
+	transactions := make([]string, len(hashes))
+	for i, hash := range hashes {
+		transactions[i] = hash.Hex()
+	}
+	EncodeResult(w, SucceedWithResult(struct {
+		Transactions []string `json:"transactions"`
+	}{transactions}))
 }
 
 func (s *Server) getAccounts(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
@@ -10,8 +10,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"goldenteam/ethclient"
 	"goldenteam/ethclient/cluster"
 )
@@ -69,11 +69,11 @@ type ContractClient struct {
 	voteAccountIndex int
 	client           *ethclient.Client
 	gethpath         *cluster.GethPath
-	contractABI      abi.ABI
+	artifact         *ethclient.Artifact
 	keyGenerator     *ethclient.KeyGenerator
 }
 
-func newContractClient(conf *cluster.Config, contract string) (*ContractClient, error) {
+func newContractClient(conf *cluster.Config, contract, artifactFile string) (*ContractClient, error) {
 	keyFilePath, err := filepath.Abs(conf.KeyStorePath)
 	if err != nil {
 		return nil, err
@@ -96,11 +96,15 @@ func newContractClient(conf *cluster.Config, contract string) (*ContractClient,
 		return nil, err
 	}
 
-	contractABI, _ := ethclient.ABIFromString(SurveyABI)
+	artifact, err := loadArtifact(artifactFile)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &ContractClient{
 		account:          account,
 		gethpath:         gethpath,
-		contractABI:      contractABI,
+		artifact:         artifact,
 		voteAccountIndex: 0,
 		keyGenerator:     keyGenerator,
 	}
@@ -110,21 +114,22 @@ func newContractClient(conf *cluster.Config, contract string) (*ContractClient,
 		c.deployContract()
 	} else {
 		c.contractAddress = common.HexToAddress(contract)
+		c.waitForContractReady()
 	}
-
-	c.waitForContractReady()
 	return c, nil
 }
 
 func (c *ContractClient) deployContract() {
-	address, err := c.client.Deploy(c.account, SurveyABI, SurveyBin, "most used any encrypted currency")
+	fmt.Printf("wait for contract ready\n")
+	contract, tx, err := ethclient.DeployArtifactContract(context.Background(), c.client, c.account, c.artifact, nil, "most used any encrypted currency")
 	if err != nil {
 		fmt.Printf("deploy contract failed %s\n", err.Error())
 		os.Exit(1)
-	} else {
-		c.contractAddress = address
-		fmt.Printf("deploy contract with address: %s\n", address.Hex())
 	}
+
+	c.contractAddress = contract.Address()
+	fmt.Printf("deploy contract with address: %s tx: %s\n", c.contractAddress.Hex(), tx.Hash().Hex())
+	fmt.Printf("contract is ready\n")
 }
 
 func (c *ContractClient) SelectNode(name string) error {
@@ -150,31 +155,40 @@ func (c *ContractClient) CurrentNodeName() string {
 	}
 }
 
+// the contract is bound to the client of current node
+func (c *ContractClient) contract() *ethclient.Contract {
+	return ethclient.NewArtifactContract(c.client, c.contractAddress, c.artifact)
+}
+
+func loadArtifact(artifactFile string) (*ethclient.Artifact, error) {
+	if artifactFile == "" {
+		return ethclient.NewArtifact("Survey", SurveyABI, SurveyBin)
+	}
+	return ethclient.LoadArtifact(artifactFile)
+}
+
 func (c *ContractClient) waitForContractReady() {
 	fmt.Printf("wait for contract ready\n")
 	for {
-		code, err := c.client.CodeAt(context.Background(), c.contractAddress, nil)
-		if err == nil && len(code) > 0 {
+		err := c.contract().WaitCode(context.Background())
+		if err == nil {
 			break
 		}
+		fmt.Printf("wait for contract failed %s\n", err.Error())
 		<-time.After(5 * time.Second)
 	}
 	fmt.Printf("contract is ready\n")
 }
 
 func (c *ContractClient) Topic() (string, error) {
-	input, _ := ethclient.PackFunctionCall(c.contractABI, "topic")
-	output, err := c.client.LocalCall(c.contractAddress, c.account, input)
+	out, err := c.contract().Call(context.Background(), "topic")
 	if err != nil {
 		return "", err
-	} else {
-		var topic string
-		err := c.contractABI.Unpack(&topic, "topic", output)
-		return topic, err
 	}
+	return out[0].(string), nil
 }
 
-func (c *ContractClient) Vote(a Answer) error {
+func (c *ContractClient) Vote(a Answer) (*types.Transaction, error) {
 	addresses := c.keyGenerator.ListAddress()
 	if c.voteAccountIndex >= len(addresses) {
 		c.voteAccountIndex = 0
@@ -182,39 +196,29 @@ func (c *ContractClient) Vote(a Answer) error {
 
 	account, err := c.keyGenerator.GetAccount(addresses[c.voteAccountIndex], cluster.DefaultPasswd)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	c.voteAccountIndex += 1
 
-	input, _ := ethclient.PackFunctionCall(c.contractABI, "vote", uint8(a))
-	return c.client.OnlineCall(c.contractAddress, account, input)
+	return c.contract().Transact(account, "vote", uint8(a))
 }
 
 func (c *ContractClient) VoteForAnswer(a Answer) (*big.Int, error) {
-	input, _ := ethclient.PackFunctionCall(c.contractABI, "voteForAnswer", uint8(a))
-	output, err := c.client.LocalCall(c.contractAddress, c.account, input)
+	out, err := c.contract().Call(context.Background(), "voteForAnswer", uint8(a))
 	if err != nil {
 		return nil, err
-	} else {
-		number := big.NewInt(0)
-		err := c.contractABI.Unpack(&number, "voteForAnswer", output)
-		return number, err
 	}
+	return out[0].(*big.Int), nil
 }
 
 func (c *ContractClient) MostVoted() (Answer, error) {
-	input, _ := ethclient.PackFunctionCall(c.contractABI, "mostVoted")
-	output, err := c.client.LocalCall(c.contractAddress, c.account, input)
+	out, err := c.contract().Call(context.Background(), "mostVoted")
 	if err != nil {
 		return 0, err
-	} else {
-		var a uint8
-		err := c.contractABI.Unpack(&a, "mostVoted", output)
-		return Answer(a), err
 	}
+	return Answer(out[0].(uint8)), nil
 }
 
-func (c *ContractClient) Finalize() error {
-	input, _ := ethclient.PackFunctionCall(c.contractABI, "finalize")
-	return c.client.OnlineCall(c.contractAddress, c.account, input)
+func (c *ContractClient) Finalize() (*types.Transaction, error) {
+	return c.contract().Transact(c.account, "finalize")
 }
@@ -8,8 +8,8 @@ import (
 	"github.com/c-bata/go-prompt"
 )
 
-func NewExecutor(c *ContractClient) func(*prompt.Prompt, string) {
-	return func(p *prompt.Prompt, in string) {
+func NewExecutor(c *ContractClient) prompt.Executor {
+	return func(in string) {
 		in = strings.TrimSpace(in)
 		if in == "" {
 			return
@@ -42,8 +42,6 @@ func NewExecutor(c *ContractClient) func(*prompt.Prompt, string) {
 		default:
 			fmt.Printf("unknown cmd %s\n", cmd)
 		}
-
-		p.SetPrefix(c.CurrentNodeName() + ">>> ")
 	}
 }
 
@@ -62,9 +60,9 @@ func cmdVote(c *ContractClient, a string) {
 		return
 	}
 
-	err = c.Vote(answer)
+	tx, err := c.Vote(answer)
 	if err == nil {
-		fmt.Printf("ok\n")
+		fmt.Printf("ok tx:%s\n", tx.Hash().Hex())
 	} else {
 		fmt.Printf("vote failed:%s\n", err.Error())
 	}
@@ -95,9 +93,9 @@ func cmdMostVoted(c *ContractClient) {
 }
 
 func cmdFinalize(c *ContractClient) {
-	err := c.Finalize()
+	tx, err := c.Finalize()
 	if err == nil {
-		fmt.Printf("ok\n")
+		fmt.Printf("ok tx:%s\n", tx.Hash().Hex())
 	} else {
 		fmt.Printf("err:%s\n", err.Error())
 	}
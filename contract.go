@@ -12,25 +12,51 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
-func (c *Client) OnlineCall(contract common.Address, from *Account, input []byte) error {
+func (c *Client) OnlineCall(contract common.Address, from Signer, input []byte) (*types.Transaction, error) {
+	return c.OnlineCallContext(context.Background(), contract, from, input)
+}
+
+func (c *Client) OnlineCallContext(ctx context.Context, contract common.Address, from Signer, input []byte) (*types.Transaction, error) {
+	return c.OnlineCallWithOpts(ctx, contract, from, input, nil)
+}
+
+func (c *Client) OnlineCallWithOpts(ctx context.Context, contract common.Address, from Signer, input []byte, opts *SendOpts) (*types.Transaction, error) {
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	fromAddress := from.Address()
-	nonce, err := c.PendingNonceAt(context.Background(), fromAddress)
+	nonce, err := c.nonces.Next(ctx, fromAddress)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rawTx := types.NewTransaction(nonce, contract, big.NewInt(0), input)
-	signedTx, err := from.SignTransaction(types.HomesteadSigner{}, rawTx)
+	rawTx, err := c.newTransaction(ctx, fromAddress, &contract, nonce, input, opts)
 	if err != nil {
-		return err
+		c.nonces.Release(fromAddress, nonce)
+		return nil, err
+	}
+	signedTx, err := signTx(ctx, from, chainID, rawTx)
+	if err != nil {
+		c.nonces.Release(fromAddress, nonce)
+		return nil, err
 	}
 
-	return c.SendTransaction(context.Background(), signedTx)
+	if err := c.SendTransaction(ctx, signedTx); err != nil {
+		c.nonces.Failed(fromAddress, nonce, err)
+		return nil, err
+	}
+	return signedTx, nil
 }
 
-func (c *Client) LocalCall(contract common.Address, from *Account, input []byte) ([]byte, error) {
+func (c *Client) LocalCall(contract common.Address, from Signer, input []byte) ([]byte, error) {
+	return c.LocalCallContext(context.Background(), contract, from, input)
+}
+
+func (c *Client) LocalCallContext(ctx context.Context, contract common.Address, from Signer, input []byte) ([]byte, error) {
 	msg := ethereum.CallMsg{From: from.Address(), To: &contract, Data: input}
-	return c.CallContract(context.Background(), msg, nil)
+	return c.CallContract(ctx, msg, nil)
 }
 
 func PackFunctionCall(api abi.ABI, method string, args ...interface{}) ([]byte, error) {
@@ -41,11 +67,66 @@ func ABIFromString(api string) (abi.ABI, error) {
 	return abi.JSON(strings.NewReader(api))
 }
 
-func (c *Client) Deploy(from *Account, api, bytecode string, params ...interface{}) (common.Address, error) {
+func (c *Client) Deploy(from Signer, api, bytecode string, params ...interface{}) (common.Address, *types.Transaction, error) {
+	return c.DeployContext(context.Background(), from, api, bytecode, params...)
+}
+
+func (c *Client) DeployContext(ctx context.Context, from Signer, api, bytecode string, params ...interface{}) (common.Address, *types.Transaction, error) {
+	return c.DeployWithOpts(ctx, from, api, bytecode, nil, params...)
+}
+
+func (c *Client) DeployWithOpts(ctx context.Context, from Signer, api, bytecode string, sendOpts *SendOpts, params ...interface{}) (common.Address, *types.Transaction, error) {
 	parsed, err := ABIFromString(api)
 	if err != nil {
-		return common.Address{}, err
+		return common.Address{}, nil, err
+	}
+	return c.deploy(ctx, from, parsed, common.FromHex(bytecode), sendOpts, params...)
+}
+
+// DeployArtifact links libraries into the bytecode of artifact and deploys it
+func (c *Client) DeployArtifact(ctx context.Context, from Signer, artifact *Artifact, libraries map[string]common.Address, params ...interface{}) (common.Address, *types.Transaction, error) {
+	return c.DeployArtifactWithOpts(ctx, from, artifact, libraries, nil, params...)
+}
+
+func (c *Client) DeployArtifactWithOpts(ctx context.Context, from Signer, artifact *Artifact, libraries map[string]common.Address, sendOpts *SendOpts, params ...interface{}) (common.Address, *types.Transaction, error) {
+	code, err := artifact.Code(libraries)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	return c.deploy(ctx, from, artifact.ABI, code, sendOpts, params...)
+}
+
+func (c *Client) deploy(ctx context.Context, from Signer, parsed abi.ABI, code []byte, sendOpts *SendOpts, params ...interface{}) (common.Address, *types.Transaction, error) {
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	input, err := parsed.Pack("", params...)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	fromAddress := from.Address()
+	nonce, err := c.nonces.Next(ctx, fromAddress)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	// only gas and fee are taken, bind does the packing and signing
+	tx, err := c.newTransaction(ctx, fromAddress, nil, nonce, append(code, input...), sendOpts)
+	if err != nil {
+		c.nonces.Release(fromAddress, nonce)
+		return common.Address{}, nil, err
+	}
+
+	opts := signerTransactor(ctx, from, chainID)
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.Value = tx.Value()
+	opts.GasLimit = tx.Gas()
+	opts.GasPrice = tx.GasPrice()
+	address, signedTx, _, err := bind.DeployContract(opts, parsed, code, c, params...)
+	if err != nil {
+		c.nonces.Failed(fromAddress, nonce, err)
+		return common.Address{}, nil, err
 	}
-	address, _, _, err := bind.DeployContract(from.Transactor(), parsed, common.FromHex(bytecode), c, params...)
-	return address, err
+	return address, signedTx, nil
 }
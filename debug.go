@@ -0,0 +1,25 @@
+package ethclient
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SetHead rewinds the chain of the node to block number
+func (ec *Client) SetHead(number uint64) error {
+	return ec.SetHeadContext(context.Background(), number)
+}
+
+func (ec *Client) SetHeadContext(ctx context.Context, number uint64) error {
+	return ec.call(ctx, nil, "debug_setHead", hexutil.Uint64(number))
+}
+
+// Verbosity sets the log level of the node, from 0 silent to 5 trace
+func (ec *Client) Verbosity(level int) error {
+	return ec.VerbosityContext(context.Background(), level)
+}
+
+func (ec *Client) VerbosityContext(ctx context.Context, level int) error {
+	return ec.call(ctx, nil, "debug_verbosity", level)
+}
@@ -0,0 +1,219 @@
+package ethclient
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrUnknownEvent = errors.New("event isn't in abi")
+	ErrInvalidLog   = errors.New("log doesn't match event")
+	ErrHashedTopic  = errors.New("indexed value of dynamic type can only be matched by hash")
+	// anonymous events have no signature topic, so a log can't tell which one
+	// it is
+	ErrAnonymousEvent = errors.New("anonymous event can't be matched by topic")
+)
+
+// Event is a log decoded with the abi of its contract. Indexed values of
+// string, bytes and array types are the keccak256 hash of the value, since
+// only the hash is in the topic. Unnamed arguments are named argN
+type Event struct {
+	Name   string
+	Values map[string]interface{}
+}
+
+func DecodeLog(contractABI abi.ABI, log *types.Log) (*Event, error) {
+	if len(log.Topics) == 0 {
+		return nil, ErrUnknownEvent
+	}
+	var anonymous []string
+	for _, event := range contractABI.Events {
+		if event.Anonymous {
+			anonymous = append(anonymous, event.Name)
+		} else if event.Id() == log.Topics[0] {
+			return decodeEvent(event, log)
+		}
+	}
+	if len(anonymous) > 0 {
+		sort.Strings(anonymous)
+		return nil, fmt.Errorf("%s: topic %s, it may be one of %s", ErrAnonymousEvent.Error(), log.Topics[0].Hex(), strings.Join(anonymous, ","))
+	}
+	return nil, fmt.Errorf("%s: topic %s", ErrUnknownEvent.Error(), log.Topics[0].Hex())
+}
+
+func decodeEvent(event abi.Event, log *types.Log) (*Event, error) {
+	var indexed, nonIndexed []abi.Argument
+	var indexedNames, nonIndexedNames []string
+	for i, arg := range event.Inputs {
+		name := argumentName(arg, i)
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+			indexedNames = append(indexedNames, name)
+		} else {
+			nonIndexed = append(nonIndexed, arg)
+			nonIndexedNames = append(nonIndexedNames, name)
+		}
+	}
+	if len(log.Topics) != len(indexed)+1 {
+		return nil, fmt.Errorf("%s: %s has %d indexed arguments but log has %d topics", ErrInvalidLog.Error(), event.Name, len(indexed), len(log.Topics))
+	}
+
+	values := make(map[string]interface{})
+	data, err := unpackArguments(nonIndexed, log.Data)
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range data {
+		values[nonIndexedNames[i]] = value
+	}
+
+	for i, arg := range indexed {
+		topic := log.Topics[i+1]
+		if isHashedTopic(arg.Type) {
+			values[indexedNames[i]] = topic
+			continue
+		}
+
+		arg.Indexed = false
+		value, err := unpackArguments([]abi.Argument{arg}, topic.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		values[indexedNames[i]] = value[0]
+	}
+
+	return &Event{
+		Name:   event.Name,
+		Values: values,
+	}, nil
+}
+
+// dynamic types and arrays are hashed in topics
+func isHashedTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy:
+		return true
+	}
+	return false
+}
+
+// topic of indexed value, value of dynamic type could be the raw value or
+// its hash
+func encodeTopic(arg abi.Argument, value interface{}) (common.Hash, error) {
+	if isHashedTopic(arg.Type) {
+		switch v := value.(type) {
+		case common.Hash:
+			return v, nil
+		case string:
+			return crypto.Keccak256Hash([]byte(v)), nil
+		case []byte:
+			return crypto.Keccak256Hash(v), nil
+		}
+		return common.Hash{}, fmt.Errorf("%s: %s", ErrHashedTopic.Error(), arg.Name)
+	}
+
+	// abi only packs inputs of a method by name
+	const name = "topic"
+	arg.Indexed = false
+	argsABI := abi.ABI{Methods: map[string]abi.Method{
+		name: {Name: name, Inputs: []abi.Argument{arg}},
+	}}
+	packed, err := argsABI.Pack(name, value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(packed[4:]), nil
+}
+
+// FilterBuilder makes the log query of an event, topics are computed from
+// the values of indexed arguments
+type FilterBuilder struct {
+	abi   abi.ABI
+	query ethereum.FilterQuery
+	err   error
+}
+
+func NewFilterBuilder(contractABI abi.ABI) *FilterBuilder {
+	return &FilterBuilder{
+		abi: contractABI,
+	}
+}
+
+func (b *FilterBuilder) SetAddresses(addresses ...common.Address) *FilterBuilder {
+	b.query.Addresses = addresses
+	return b
+}
+
+// block nil is the latest block
+func (b *FilterBuilder) SetBlockRange(from, to *big.Int) *FilterBuilder {
+	b.query.FromBlock = from
+	b.query.ToBlock = to
+	return b
+}
+
+// SetEvent matches the event with the values of its indexed arguments in
+// order, nil matches any value and []interface{} matches any of the values
+func (b *FilterBuilder) SetEvent(name string, args ...interface{}) *FilterBuilder {
+	event, ok := b.abi.Events[name]
+	if ok == false {
+		b.err = fmt.Errorf("%s: %s", ErrUnknownEvent.Error(), name)
+		return b
+	} else if event.Anonymous {
+		b.err = fmt.Errorf("%s: %s", ErrAnonymousEvent.Error(), name)
+		return b
+	}
+
+	var indexed []abi.Argument
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if len(args) > len(indexed) {
+		b.err = fmt.Errorf("%s: %s has %d indexed arguments", ErrInvalidLog.Error(), name, len(indexed))
+		return b
+	}
+
+	topics := [][]common.Hash{{event.Id()}}
+	for i, arg := range args {
+		var values []interface{}
+		switch v := arg.(type) {
+		case nil:
+		case []interface{}:
+			values = v
+		default:
+			values = []interface{}{v}
+		}
+
+		var hashes []common.Hash
+		for _, value := range values {
+			topic, err := encodeTopic(indexed[i], value)
+			if err != nil {
+				b.err = err
+				return b
+			}
+			hashes = append(hashes, topic)
+		}
+		topics = append(topics, hashes)
+	}
+
+	// trailing wildcards are not needed
+	for len(topics) > 1 && len(topics[len(topics)-1]) == 0 {
+		topics = topics[:len(topics)-1]
+	}
+	b.query.Topics = topics
+	return b
+}
+
+func (b *FilterBuilder) Build() (ethereum.FilterQuery, error) {
+	return b.query, b.err
+}
@@ -0,0 +1,176 @@
+package ethclient
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const testEventABI = `[
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}
+	]},
+	{"type":"event","name":"Note","anonymous":false,"inputs":[
+		{"name":"sender","type":"address","indexed":true},
+		{"name":"tag","type":"string","indexed":true},
+		{"name":"amount","type":"uint256","indexed":false},
+		{"name":"","type":"bytes32","indexed":false}
+	]},
+	{"type":"event","name":"Batch","anonymous":false,"inputs":[
+		{"name":"ids","type":"uint256[]","indexed":true},
+		{"name":"pair","type":"uint8[2]","indexed":true},
+		{"name":"ok","type":"bool","indexed":true}
+	]},
+	{"type":"event","name":"Raw","anonymous":true,"inputs":[
+		{"name":"value","type":"uint256","indexed":true}
+	]}
+]`
+
+var (
+	// keccak256 of Transfer(address,address,uint256)
+	transferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	// keccak256 of "hello"
+	helloTopic = common.HexToHash("0x1c8aff950685c2ed4bc3174f3472287b56d9517b9c948127319a09a7a36deac8")
+
+	testSender   = common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+	testReceiver = common.HexToAddress("0x7dbd46c0b9a4b80d5f9d4b3dc10cbc2bbbe6f7d4")
+)
+
+func TestDecodeLog(t *testing.T) {
+	contractABI, err := ABIFromString(testEventABI)
+	if err != nil {
+		t.Fatalf("abi: %v", err)
+	}
+	if id := contractABI.Events["Transfer"].Id(); id != transferTopic {
+		t.Fatalf("event id mismatch: have %s, want %s", id.Hex(), transferTopic.Hex())
+	}
+
+	tag := common.Hash{0xaa}
+	noteLog := &types.Log{
+		Topics: []common.Hash{contractABI.Events["Note"].Id(), testSender.Hash(), helloTopic},
+		Data:   append(math.PaddedBigBytes(big.NewInt(42), 32), tag[:]...),
+	}
+	event, err := DecodeLog(contractABI, noteLog)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if event.Name != "Note" || len(event.Values) != 4 {
+		t.Fatalf("event mismatch: %+v", event)
+	}
+	if event.Values["sender"] != testSender {
+		t.Errorf("sender mismatch: have %v, want %s", event.Values["sender"], testSender.Hex())
+	}
+	// only the hash of indexed string is in the log
+	if event.Values["tag"] != helloTopic {
+		t.Errorf("tag mismatch: have %v, want %s", event.Values["tag"], helloTopic.Hex())
+	}
+	if amount, ok := event.Values["amount"].(*big.Int); !ok || amount.Int64() != 42 {
+		t.Errorf("amount mismatch: have %v, want 42", event.Values["amount"])
+	}
+	if event.Values["arg3"] != [32]byte(tag) {
+		t.Errorf("unnamed value mismatch: have %v, want %x", event.Values["arg3"], tag)
+	}
+
+	tests := []struct {
+		log *types.Log
+		err error
+	}{
+		{&types.Log{}, ErrUnknownEvent},
+		{&types.Log{Topics: []common.Hash{transferTopic, testSender.Hash()}, Data: make([]byte, 32)}, ErrInvalidLog},
+		{&types.Log{Topics: []common.Hash{transferTopic, testSender.Hash(), testReceiver.Hash(), {}}, Data: make([]byte, 32)}, ErrInvalidLog},
+		// no event has the topic, it may be the anonymous one
+		{&types.Log{Topics: []common.Hash{helloTopic}}, ErrAnonymousEvent},
+	}
+	for i, test := range tests {
+		if _, err := DecodeLog(contractABI, test.log); err == nil || !strings.HasPrefix(err.Error(), test.err.Error()) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+}
+
+func TestFilterBuilder(t *testing.T) {
+	contractABI, err := ABIFromString(testEventABI)
+	if err != nil {
+		t.Fatalf("abi: %v", err)
+	}
+	noteTopic := contractABI.Events["Note"].Id()
+	batchTopic := contractABI.Events["Batch"].Id()
+
+	tests := []struct {
+		event  string
+		args   []interface{}
+		topics [][]common.Hash
+		err    error
+	}{
+		{"Transfer", nil, [][]common.Hash{{transferTopic}}, nil},
+		{"Transfer", []interface{}{testSender}, [][]common.Hash{{transferTopic}, {testSender.Hash()}}, nil},
+		// leading wildcard is kept, trailing ones are trimmed
+		{"Transfer", []interface{}{nil, testReceiver}, [][]common.Hash{{transferTopic}, nil, {testReceiver.Hash()}}, nil},
+		{"Transfer", []interface{}{testSender, nil}, [][]common.Hash{{transferTopic}, {testSender.Hash()}}, nil},
+		// any of the values
+		{"Transfer", []interface{}{[]interface{}{testSender, testReceiver}}, [][]common.Hash{{transferTopic}, {testSender.Hash(), testReceiver.Hash()}}, nil},
+		// string is hashed, its hash is taken as is
+		{"Note", []interface{}{nil, "hello"}, [][]common.Hash{{noteTopic}, nil, {helloTopic}}, nil},
+		{"Note", []interface{}{nil, []byte("hello")}, [][]common.Hash{{noteTopic}, nil, {helloTopic}}, nil},
+		{"Note", []interface{}{nil, helloTopic}, [][]common.Hash{{noteTopic}, nil, {helloTopic}}, nil},
+		{"Batch", []interface{}{helloTopic, helloTopic, true}, [][]common.Hash{{batchTopic}, {helloTopic}, {helloTopic}, {common.BigToHash(big.NewInt(1))}}, nil},
+		{"Batch", []interface{}{[]*big.Int{big.NewInt(1)}}, nil, ErrHashedTopic},
+		{"Transfer", []interface{}{nil, nil, big.NewInt(1)}, nil, ErrInvalidLog},
+		{"Missing", nil, nil, ErrUnknownEvent},
+		{"Raw", nil, nil, ErrAnonymousEvent},
+	}
+	for i, test := range tests {
+		query, err := NewFilterBuilder(contractABI).SetEvent(test.event, test.args...).Build()
+		if test.err != nil {
+			if err == nil || !strings.HasPrefix(err.Error(), test.err.Error()) {
+				t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("test %d: build: %v", i, err)
+			continue
+		}
+		if len(query.Topics) != len(test.topics) {
+			t.Errorf("test %d: topics mismatch: have %v, want %v", i, query.Topics, test.topics)
+			continue
+		}
+		for j := range test.topics {
+			if len(query.Topics[j]) != len(test.topics[j]) {
+				t.Errorf("test %d: topic %d mismatch: have %v, want %v", i, j, query.Topics[j], test.topics[j])
+				continue
+			}
+			for k := range test.topics[j] {
+				if query.Topics[j][k] != test.topics[j][k] {
+					t.Errorf("test %d: topic %d mismatch: have %v, want %v", i, j, query.Topics[j], test.topics[j])
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeTopic(t *testing.T) {
+	contractABI, err := ABIFromString(testEventABI)
+	if err != nil {
+		t.Fatalf("abi: %v", err)
+	}
+	transfer := contractABI.Events["Transfer"]
+
+	// static values are left padded to 32 bytes, without the selector
+	topic, err := encodeTopic(transfer.Inputs[0], testSender)
+	if err != nil || topic != common.BytesToHash(testSender[:]) {
+		t.Errorf("address topic mismatch: have %s %v", topic.Hex(), err)
+	}
+	topic, err = encodeTopic(transfer.Inputs[2], big.NewInt(0x1234))
+	if err != nil || topic != common.HexToHash("0x1234") {
+		t.Errorf("uint topic mismatch: have %s %v", topic.Hex(), err)
+	}
+	if _, err := encodeTopic(transfer.Inputs[0], "not an address"); err == nil {
+		t.Error("value of wrong type is encoded")
+	}
+}
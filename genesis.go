@@ -3,9 +3,10 @@ package ethclient
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"math/big"
-	"math/rand"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -13,46 +14,335 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 )
 
-func MakeGenesis(blockInterval time.Duration, signers []common.Address, prefund map[common.Address]*big.Int) *core.Genesis {
+const (
+	// same as the default network id of cluster nodes
+	DefaultChainID     = 77877
+	DefaultCliqueEpoch = 30
+	// minimum difficulty of ethash, blocks are mined in seconds by one cpu
+	DefaultEthashDifficulty = 131072
+	extraVanityLength       = 32
+	extraSealLength         = 65
+)
+
+var (
+	ErrInvalidGenesis = errors.New("invalid genesis")
+)
+
+// GenesisBuilder makes a clique genesis, or an ethash genesis after
+// SetEthash, all forks are enabled from block 0 unless they are set
+type GenesisBuilder struct {
+	ethash         bool
+	difficulty     *big.Int
+	chainID        *big.Int
+	homesteadBlock *big.Int
+	eip150Block    *big.Int
+	eip155Block    *big.Int
+	eip158Block    *big.Int
+	byzantiumBlock *big.Int
+	period         uint64
+	epoch          uint64
+	gasLimit       uint64
+	timestamp      uint64
+	vanity         [extraVanityLength]byte
+	signers        []common.Address
+	alloc          core.GenesisAlloc
+}
+
+func NewGenesisBuilder() *GenesisBuilder {
+	return &GenesisBuilder{
+		chainID:        big.NewInt(DefaultChainID),
+		homesteadBlock: big.NewInt(0),
+		eip150Block:    big.NewInt(0),
+		eip155Block:    big.NewInt(0),
+		eip158Block:    big.NewInt(0),
+		byzantiumBlock: big.NewInt(0),
+		epoch:          DefaultCliqueEpoch,
+		timestamp:      uint64(time.Now().Unix()),
+		alloc:          make(core.GenesisAlloc),
+	}
+}
+
+// SetEthash makes a proof of work genesis with low difficulty, signers and
+// clique settings are ignored
+func (b *GenesisBuilder) SetEthash() *GenesisBuilder {
+	b.ethash = true
+	return b
+}
+
+// difficulty nil uses 1 for clique and DefaultEthashDifficulty for ethash
+func (b *GenesisBuilder) SetDifficulty(difficulty *big.Int) *GenesisBuilder {
+	b.difficulty = difficulty
+	return b
+}
+
+// chain id 0 can't be used for replay protected transactions
+func (b *GenesisBuilder) SetChainID(chainID *big.Int) *GenesisBuilder {
+	b.chainID = new(big.Int).Set(chainID)
+	return b
+}
+
+// fork block nil disables the fork
+func (b *GenesisBuilder) SetHomesteadBlock(number *big.Int) *GenesisBuilder {
+	b.homesteadBlock = number
+	return b
+}
+
+func (b *GenesisBuilder) SetEIP150Block(number *big.Int) *GenesisBuilder {
+	b.eip150Block = number
+	return b
+}
+
+func (b *GenesisBuilder) SetEIP155Block(number *big.Int) *GenesisBuilder {
+	b.eip155Block = number
+	return b
+}
+
+func (b *GenesisBuilder) SetEIP158Block(number *big.Int) *GenesisBuilder {
+	b.eip158Block = number
+	return b
+}
+
+func (b *GenesisBuilder) SetByzantiumBlock(number *big.Int) *GenesisBuilder {
+	b.byzantiumBlock = number
+	return b
+}
+
+func (b *GenesisBuilder) SetPeriod(blockInterval time.Duration) *GenesisBuilder {
+	b.period = uint64(blockInterval.Seconds())
+	return b
+}
+
+func (b *GenesisBuilder) SetEpoch(epoch uint64) *GenesisBuilder {
+	b.epoch = epoch
+	return b
+}
+
+// gas limit 0 uses the default of geth
+func (b *GenesisBuilder) SetGasLimit(gasLimit uint64) *GenesisBuilder {
+	b.gasLimit = gasLimit
+	return b
+}
+
+// SetTimestamp fixes the genesis time, so the same settings always build the
+// same genesis block. The default is the time the builder is made
+func (b *GenesisBuilder) SetTimestamp(timestamp time.Time) *GenesisBuilder {
+	b.timestamp = uint64(timestamp.Unix())
+	return b
+}
+
+// vanity longer than 32 bytes is truncated
+func (b *GenesisBuilder) SetExtraVanity(vanity []byte) *GenesisBuilder {
+	b.vanity = [extraVanityLength]byte{}
+	copy(b.vanity[:], vanity)
+	return b
+}
+
+func (b *GenesisBuilder) SetSigners(signers []common.Address) *GenesisBuilder {
+	b.signers = append([]common.Address(nil), signers...)
+	return b
+}
+
+func (b *GenesisBuilder) Prefund(address common.Address, balance *big.Int) *GenesisBuilder {
+	account := b.alloc[address]
+	account.Balance = balance
+	b.alloc[address] = account
+	return b
+}
+
+// DeployContract puts runtime code and storage at address, so the contract
+// exists from block 0
+func (b *GenesisBuilder) DeployContract(address common.Address, code []byte, storage map[common.Hash]common.Hash, balance *big.Int) *GenesisBuilder {
+	if balance == nil {
+		balance = new(big.Int)
+	}
+	b.alloc[address] = core.GenesisAccount{
+		Code:    code,
+		Storage: storage,
+		Balance: balance,
+	}
+	return b
+}
+
+func (b *GenesisBuilder) Build() *core.Genesis {
 	genesis := &core.Genesis{
-		Timestamp:  uint64(time.Now().Unix()),
-		Difficulty: big.NewInt(1048576),
+		Timestamp:  b.timestamp,
+		GasLimit:   b.gasLimit,
+		Difficulty: b.difficulty,
 		Alloc:      make(core.GenesisAlloc),
-		Config:     &params.ChainConfig{},
-	}
-	genesis.Difficulty = big.NewInt(1)
-	genesis.Config.Clique = &params.CliqueConfig{
-		Period: uint64(blockInterval.Seconds()),
-		Epoch:  30,
+		Config: &params.ChainConfig{
+			ChainId:        b.chainID,
+			HomesteadBlock: b.homesteadBlock,
+			EIP150Block:    b.eip150Block,
+			EIP155Block:    b.eip155Block,
+			EIP158Block:    b.eip158Block,
+			ByzantiumBlock: b.byzantiumBlock,
+		},
 	}
 
-	for i := 0; i < len(signers); i++ {
-		for j := i + 1; j < len(signers); j++ {
-			if bytes.Compare(signers[i][:], signers[j][:]) > 0 {
-				signers[i], signers[j] = signers[j], signers[i]
-			}
+	if b.ethash {
+		if genesis.Difficulty == nil {
+			genesis.Difficulty = big.NewInt(DefaultEthashDifficulty)
 		}
+		genesis.Config.Ethash = new(params.EthashConfig)
+		genesis.ExtraData = append([]byte(nil), bytes.TrimRight(b.vanity[:], "\x00")...)
+	} else {
+		if genesis.Difficulty == nil {
+			genesis.Difficulty = big.NewInt(1)
+		}
+		genesis.Config.Clique = &params.CliqueConfig{
+			Period: b.period,
+			Epoch:  b.epoch,
+		}
+		b.fillSigners(genesis)
 	}
-	genesis.ExtraData = make([]byte, 32+len(signers)*common.AddressLength+65)
+
+	b.fillAlloc(genesis)
+	return genesis
+}
+
+func (b *GenesisBuilder) fillSigners(genesis *core.Genesis) {
+	signers := append([]common.Address(nil), b.signers...)
+	sortAddresses(signers)
+	genesis.ExtraData = make([]byte, extraVanityLength+len(signers)*common.AddressLength+extraSealLength)
+	copy(genesis.ExtraData, b.vanity[:])
 	for i, signer := range signers {
-		copy(genesis.ExtraData[32+i*common.AddressLength:], signer[:])
+		copy(genesis.ExtraData[extraVanityLength+i*common.AddressLength:], signer[:])
 	}
+}
 
-	for address, fund := range prefund {
-		genesis.Alloc[address] = core.GenesisAccount{
-			Balance: fund,
-		}
-	}
+func (b *GenesisBuilder) fillAlloc(genesis *core.Genesis) {
 	// Add a batch of precompile balances to avoid them getting deleted
 	for i := int64(0); i < 256; i++ {
 		genesis.Alloc[common.BigToAddress(big.NewInt(i))] = core.GenesisAccount{Balance: big.NewInt(1)}
 	}
+	for address, account := range b.alloc {
+		if account.Balance == nil {
+			account.Balance = new(big.Int)
+		}
+		genesis.Alloc[address] = account
+	}
+}
 
-	genesis.Config.ChainId = new(big.Int).SetUint64(uint64(rand.Intn(65536)))
-	return genesis
+func MakeGenesis(blockInterval time.Duration, signers []common.Address, prefund map[common.Address]*big.Int) *core.Genesis {
+	b := NewGenesisBuilder().SetPeriod(blockInterval).SetSigners(signers)
+	for address, fund := range prefund {
+		b.Prefund(address, fund)
+	}
+	return b.Build()
 }
 
 func CreateGensisFile(genesis *core.Genesis, fileName string) error {
-	out, _ := json.MarshalIndent(genesis, "", "  ")
+	out, err := json.MarshalIndent(genesis, "", "  ")
+	if err != nil {
+		return err
+	}
 	return ioutil.WriteFile(fileName, out, 0644)
 }
+
+// LoadGenesis reads and validates a genesis file
+func LoadGenesis(fileName string) (*core.Genesis, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGenesis(data)
+}
+
+func ParseGenesis(data []byte) (*core.Genesis, error) {
+	genesis := new(core.Genesis)
+	if err := json.Unmarshal(data, genesis); err != nil {
+		return nil, err
+	}
+	if err := ValidateGenesis(genesis); err != nil {
+		return nil, err
+	}
+	return genesis, nil
+}
+
+// ValidateGenesis checks the consensus config, the signers in extra data of
+// clique genesis and the order of fork blocks
+func ValidateGenesis(genesis *core.Genesis) error {
+	config := genesis.Config
+	if config == nil {
+		return fmt.Errorf("%s: no chain config", ErrInvalidGenesis.Error())
+	}
+
+	switch {
+	case config.Clique != nil && config.Ethash != nil:
+		return fmt.Errorf("%s: both clique and ethash are configured", ErrInvalidGenesis.Error())
+	case config.Clique != nil:
+		if config.Clique.Epoch == 0 {
+			return fmt.Errorf("%s: clique epoch is 0", ErrInvalidGenesis.Error())
+		}
+		if _, err := GenesisSigners(genesis); err != nil {
+			return err
+		}
+	case config.Ethash != nil:
+		if genesis.Difficulty == nil || genesis.Difficulty.Sign() <= 0 {
+			return fmt.Errorf("%s: ethash difficulty isn't positive", ErrInvalidGenesis.Error())
+		}
+	default:
+		return fmt.Errorf("%s: no clique or ethash config", ErrInvalidGenesis.Error())
+	}
+
+	if config.EIP155Block != nil && (config.ChainId == nil || config.ChainId.Sign() <= 0) {
+		return fmt.Errorf("%s: eip155 needs a positive chain id", ErrInvalidGenesis.Error())
+	}
+	return checkForkOrder(config)
+}
+
+// a fork can't be enabled when the previous one is disabled or comes later
+func checkForkOrder(config *params.ChainConfig) error {
+	forks := []struct {
+		name  string
+		block *big.Int
+	}{
+		{"homestead", config.HomesteadBlock},
+		{"eip150", config.EIP150Block},
+		{"eip155", config.EIP155Block},
+		{"eip158", config.EIP158Block},
+		{"byzantium", config.ByzantiumBlock},
+	}
+
+	for i := 1; i < len(forks); i++ {
+		prev, cur := forks[i-1], forks[i]
+		if cur.block == nil {
+			continue
+		}
+		if prev.block == nil {
+			return fmt.Errorf("%s: %s is enabled at %v but %s is disabled", ErrInvalidGenesis.Error(), cur.name, cur.block, prev.name)
+		} else if prev.block.Cmp(cur.block) > 0 {
+			return fmt.Errorf("%s: %s at %v is before %s at %v", ErrInvalidGenesis.Error(), cur.name, cur.block, prev.name, prev.block)
+		}
+	}
+	return nil
+}
+
+// GenesisSigners returns the clique signers in extra data, which must be
+// sorted and unique
+func GenesisSigners(genesis *core.Genesis) ([]common.Address, error) {
+	extra := genesis.ExtraData
+	if len(extra) < extraVanityLength+extraSealLength {
+		return nil, fmt.Errorf("%s: extra data is too short", ErrInvalidGenesis.Error())
+	}
+	signersBytes := extra[extraVanityLength : len(extra)-extraSealLength]
+	if len(signersBytes) == 0 || len(signersBytes)%common.AddressLength != 0 {
+		return nil, fmt.Errorf("%s: extra data has %d bytes for signers", ErrInvalidGenesis.Error(), len(signersBytes))
+	}
+
+	signers := make([]common.Address, len(signersBytes)/common.AddressLength)
+	for i := range signers {
+		copy(signers[i][:], signersBytes[i*common.AddressLength:])
+		if i > 0 && bytes.Compare(signers[i-1][:], signers[i][:]) >= 0 {
+			return nil, fmt.Errorf("%s: signers aren't sorted or unique", ErrInvalidGenesis.Error())
+		}
+	}
+	return signers, nil
+}
+
+// GenesisHash computes the hash and state root of the genesis block, which
+// is what nodes initialised from the same genesis agree on
+func GenesisHash(genesis *core.Genesis) (hash common.Hash, stateRoot common.Hash) {
+	block, _ := genesis.ToBlock()
+	return block.Hash(), block.Root()
+}
@@ -0,0 +1,106 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	testSignerA = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testSignerB = common.HexToAddress("0x2000000000000000000000000000000000000002")
+)
+
+func TestGenesisBuilderIsReproducible(t *testing.T) {
+	build := func(funded ...common.Address) *core.Genesis {
+		b := NewGenesisBuilder().
+			SetTimestamp(time.Unix(0, 0)).
+			SetPeriod(time.Second).
+			SetSigners([]common.Address{testSignerB, testSignerA})
+		for _, address := range funded {
+			b.Prefund(address, big.NewInt(1000))
+		}
+		return b.Build()
+	}
+
+	first, second := build(testSignerA, testSignerB), build(testSignerB, testSignerA)
+	if first.Timestamp != 0 {
+		t.Fatalf("timestamp mismatch: have %d, want 0", first.Timestamp)
+	}
+	firstHash, _ := GenesisHash(first)
+	secondHash, _ := GenesisHash(second)
+	if firstHash != secondHash {
+		t.Fatalf("genesis hash differs: %x, %x", firstHash, secondHash)
+	}
+
+	signers, err := GenesisSigners(first)
+	if err != nil {
+		t.Fatalf("signers: %v", err)
+	}
+	if !reflect.DeepEqual(signers, []common.Address{testSignerA, testSignerB}) {
+		t.Fatalf("signers mismatch: %x", signers)
+	}
+}
+
+func TestParseGenesis(t *testing.T) {
+	built := NewGenesisBuilder().SetTimestamp(time.Unix(0, 0)).SetSigners([]common.Address{testSignerA}).Build()
+	data, err := json.Marshal(built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseGenesis(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	builtHash, _ := GenesisHash(built)
+	parsedHash, _ := GenesisHash(parsed)
+	if builtHash != parsedHash {
+		t.Fatalf("genesis hash mismatch: have %x, want %x", parsedHash, builtHash)
+	}
+}
+
+func TestValidateGenesis(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*core.Genesis)
+		err    string
+	}{
+		{"valid clique", func(*core.Genesis) {}, ""},
+		{"no config", func(g *core.Genesis) { g.Config = nil }, "no chain config"},
+		{"both engines", func(g *core.Genesis) { g.Config.Ethash = new(params.EthashConfig) }, "both clique and ethash"},
+		{"no engine", func(g *core.Genesis) { g.Config.Clique = nil }, "no clique or ethash"},
+		{"zero epoch", func(g *core.Genesis) { g.Config.Clique.Epoch = 0 }, "epoch is 0"},
+		{"unsorted signers", func(g *core.Genesis) {
+			copy(g.ExtraData[extraVanityLength:], testSignerB[:])
+			copy(g.ExtraData[extraVanityLength+common.AddressLength:], testSignerA[:])
+		}, "sorted"},
+		{"short extra", func(g *core.Genesis) { g.ExtraData = g.ExtraData[:extraVanityLength] }, "too short"},
+		{"fork disabled", func(g *core.Genesis) { g.Config.EIP150Block = nil }, "eip150 is disabled"},
+		{"fork order", func(g *core.Genesis) { g.Config.EIP155Block = big.NewInt(10) }, "before eip155"},
+		{"no chain id", func(g *core.Genesis) { g.Config.ChainId = nil }, "chain id"},
+	}
+	for _, test := range tests {
+		genesis := NewGenesisBuilder().SetSigners([]common.Address{testSignerA, testSignerB}).Build()
+		test.modify(genesis)
+		err := ValidateGenesis(genesis)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error mismatch: have %v, want %q", test.name, err, test.err)
+		}
+	}
+
+	ethash := NewGenesisBuilder().SetEthash().Build()
+	if err := ValidateGenesis(ethash); err != nil {
+		t.Errorf("valid ethash: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+module goldenteam/ethclient
+
+go 1.21
+
+require (
+	github.com/c-bata/go-prompt v0.2.6
+	github.com/ethereum/go-ethereum v1.7.3
+	github.com/julienschmidt/httprouter v1.3.0
+	github.com/pborman/uuid v0.0.0-20170112150404-1b00554d8222
+	github.com/tyler-smith/go-bip39 v1.1.0
+)
+
+require (
+	github.com/aristanetworks/goarista v0.0.0-20170210015632-ea17b1a17847 // indirect
+	github.com/btcsuite/btcd v0.20.1-beta // indirect
+	github.com/cespare/cp v0.1.0 // indirect
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/edsrzf/mmap-go v0.0.0-20160512033002-935e0e8a636c // indirect
+	github.com/go-stack/stack v1.5.4 // indirect
+	github.com/golang/snappy v0.0.0-20170215233205-553a64147049 // indirect
+	github.com/hashicorp/golang-lru v0.0.0-20160813221303-0a025b7e63ad // indirect
+	github.com/huin/goupnp v1.3.0 // indirect
+	github.com/jackpal/go-nat-pmp v1.1.0 // indirect
+	github.com/mattn/go-colorable v0.1.7 // indirect
+	github.com/mattn/go-isatty v0.0.12 // indirect
+	github.com/mattn/go-runewidth v0.0.9 // indirect
+	github.com/mattn/go-tty v0.0.3 // indirect
+	github.com/pkg/term v1.2.0-beta.2 // indirect
+	github.com/rcrowley/go-metrics v0.0.0-20161128210544-1f30fe9094a5 // indirect
+	github.com/rjeczalik/notify v0.9.1 // indirect
+	github.com/rs/cors v1.11.1 // indirect
+	github.com/syndtr/goleveldb v0.0.0-20170725064836-b89cc31ef797 // indirect
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9 // indirect
+	golang.org/x/net v0.0.0-20190404232315-eb5bcb51f2a3 // indirect
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
+	golang.org/x/sys v0.0.0-20200918174421-af09f7315aff // indirect
+	golang.org/x/tools v0.0.0-20170215214335-be0fcc31ae23 // indirect
+	gopkg.in/fatih/set.v0 v0.1.0 // indirect
+	gopkg.in/karalabe/cookiejar.v2 v2.0.0-20150724131613-8dcd6a7f4951 // indirect
+	gopkg.in/natefinch/npipe.v2 v2.0.0-20160621034901-c1b8fa8bdcce // indirect
+)
@@ -0,0 +1,155 @@
+package ethclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// base path used by most ethereum wallets, the last level is the index
+const DefaultHDBasePath = "m/44'/60'/0'/0"
+
+const hardenedKeyStart = 0x80000000
+
+var (
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+	errInvalidChildKey = errors.New("derived key is invalid")
+)
+
+// HDWallet derives accounts from a bip39 mnemonic along bip44 paths
+type HDWallet struct {
+	masterKey       *big.Int
+	masterChainCode []byte
+}
+
+func NewHDWallet(mnemonic, passphrase string) (*HDWallet, error) {
+	if bip39.IsMnemonicValid(mnemonic) == false {
+		return nil, ErrInvalidMnemonic
+	}
+	return NewHDWalletFromSeed(bip39.NewSeed(mnemonic, passphrase))
+}
+
+func NewHDWalletFromSeed(seed []byte) (*HDWallet, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, errInvalidChildKey
+	}
+
+	return &HDWallet{
+		masterKey:       key,
+		masterChainCode: sum[32:],
+	}, nil
+}
+
+// NewMnemonic returns a random mnemonic of 12 words
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(128)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// Derive returns the account at path like m/44'/60'/0'/0/0, the account has
+// no password until SetPassword is called
+func (w *HDWallet) Derive(path string) (*Account, error) {
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return w.DerivePath(derivationPath)
+}
+
+func (w *HDWallet) DerivePath(path accounts.DerivationPath) (*Account, error) {
+	key, chainCode := w.masterKey, w.masterChainCode
+	for _, index := range path {
+		var err error
+		if key, chainCode, err = deriveChild(key, chainCode, index); err != nil {
+			return nil, fmt.Errorf("%s at %v", err.Error(), path)
+		}
+	}
+
+	privateKey, err := crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+	if err != nil {
+		return nil, err
+	}
+	return NewAccountFromKey(privateKey), nil
+}
+
+// Accounts returns the first n accounts under DefaultHDBasePath
+func (w *HDWallet) Accounts(n int) ([]*Account, error) {
+	var accounts []*Account
+	for i := 0; i < n; i++ {
+		account, err := w.Derive(fmt.Sprintf("%s/%d", DefaultHDBasePath, i))
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+func (w *HDWallet) Addresses(n int) ([]common.Address, error) {
+	accounts, err := w.Accounts(n)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]common.Address, len(accounts))
+	for i, account := range accounts {
+		addresses[i] = account.Address()
+	}
+	return addresses, nil
+}
+
+// bip32 private parent key to private child key
+func deriveChild(key *big.Int, chainCode []byte, index uint32) (*big.Int, []byte, error) {
+	var data []byte
+	if index >= hardenedKeyStart {
+		data = append([]byte{0}, math.PaddedBigBytes(key, 32)...)
+	} else {
+		data = compressedPubkey(key)
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := crypto.S256().Params().N
+	childKey := new(big.Int).SetBytes(sum[:32])
+	if childKey.Cmp(n) >= 0 {
+		return nil, nil, errInvalidChildKey
+	}
+	childKey.Add(childKey, key)
+	childKey.Mod(childKey, n)
+	if childKey.Sign() == 0 {
+		return nil, nil, errInvalidChildKey
+	}
+	return childKey, sum[32:], nil
+}
+
+func compressedPubkey(key *big.Int) []byte {
+	curve := crypto.S256()
+	x, y := curve.ScalarBaseMult(math.PaddedBigBytes(key, 32))
+	prefix := byte(2)
+	if y.Bit(0) == 1 {
+		prefix = 3
+	}
+	return append([]byte{prefix}, math.PaddedBigBytes(x, 32)...)
+}
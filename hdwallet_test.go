@@ -0,0 +1,114 @@
+package ethclient
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type bip32Vector struct {
+	path      string
+	chainCode string
+	key       string
+}
+
+// test vectors 1 and 2 of bip32
+var bip32Tests = []struct {
+	seed    string
+	vectors []bip32Vector
+}{
+	{
+		seed: "000102030405060708090a0b0c0d0e0f",
+		vectors: []bip32Vector{
+			{"m", "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+			{"m/0'", "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+			{"m/0'/1", "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+			{"m/0'/1/2'", "04466b9cc8e161e966409ca52986c584f07e9dc81f735db683c3ff6ec7b1503f", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+			{"m/0'/1/2'/2", "cfb71883f01676f587d023cc53a35bc7f88f724b1f8c2892ac1275ac822a3edd", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+			{"m/0'/1/2'/2/1000000000", "c783e67b921d2beb8f6b389cc646d7263b4145701dadd2161548a8b078e65e9e", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+		},
+	},
+	{
+		seed: "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		vectors: []bip32Vector{
+			{"m", "60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689", "4b03d6fc340455b363f51020ad3ecca4f0850280cf436c70c727923f6db46c3e"},
+			{"m/0", "f0909affaa7ee7abe5dd4e100598d4dc53cd709d5a5c2cac40e7412f232f7c9c", "abe74a98f6c7eabee0428f53798f0ab8aa1bd37873999041703c742f15ac7e1e"},
+			{"m/0/2147483647'", "be17a268474a6bb9c61e1d720cf6215e2a88c5406c4aee7b38547f585c9a37d9", "877c779ad9687164e9c2f4f0f4ff0340814392330693ce95a58fe18fd52e6e93"},
+			{"m/0/2147483647'/1", "f366f48f1ea9f2d1d3fe958c95ca84ea18e4c4ddb9366c336c927eb246fb38cb", "704addf544a06e5ee4bea37098463c23613da32020d604506da8c0518e1da4b7"},
+			{"m/0/2147483647'/1/2147483646'", "637807030d55d01f9a0cb3a7839515d796bd07706386a6eddf06cc29a65a0e29", "f1c7c871a54a804afe328b4c83a1c33b8e5ff48f5087273f04efa83b247d6a2d"},
+			{"m/0/2147483647'/1/2147483646'/2", "9452b549be8cea3ecb7a84bec10dcfd94afe4d129ebfd3b3cb58eedf394ed271", "bb7d39bdb83ecf58f2fd82b6d918341cbef428661ef01ab97c28a4842125ac23"},
+		},
+	},
+}
+
+func TestBIP32Vectors(t *testing.T) {
+	for _, test := range bip32Tests {
+		w, err := NewHDWalletFromSeed(common.FromHex(test.seed))
+		if err != nil {
+			t.Fatalf("seed %s: %v", test.seed, err)
+		}
+		for _, v := range test.vectors {
+			key, chainCode := w.masterKey, w.masterChainCode
+			if v.path != "m" {
+				path, err := accounts.ParseDerivationPath(v.path)
+				if err != nil {
+					t.Fatalf("parse %s: %v", v.path, err)
+				}
+				for _, index := range path {
+					if key, chainCode, err = deriveChild(key, chainCode, index); err != nil {
+						t.Fatalf("derive %s: %v", v.path, err)
+					}
+				}
+
+				account, err := w.DerivePath(path)
+				if err != nil {
+					t.Fatalf("derive account %s: %v", v.path, err)
+				}
+				if got := account.PrivateKey(); got != v.key {
+					t.Errorf("%s: account key mismatch: have %s, want %s", v.path, got, v.key)
+				}
+			}
+			if got := hex.EncodeToString(chainCode); got != v.chainCode {
+				t.Errorf("%s: chain code mismatch: have %s, want %s", v.path, got, v.chainCode)
+			}
+			if got := hex.EncodeToString(math.PaddedBigBytes(key, 32)); got != v.key {
+				t.Errorf("%s: key mismatch: have %s, want %s", v.path, got, v.key)
+			}
+		}
+	}
+}
+
+func TestBIP44Mnemonic(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	w, err := NewHDWallet(mnemonic, "")
+	if err != nil {
+		t.Fatalf("new wallet: %v", err)
+	}
+
+	account, err := w.Derive("m/44'/60'/0'/0/0")
+	if err != nil {
+		t.Fatalf("derive: %v", err)
+	}
+	want := common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94")
+	if account.Address() != want {
+		t.Fatalf("address mismatch: have %x, want %x", account.Address(), want)
+	}
+
+	addresses, err := w.Addresses(1)
+	if err != nil || addresses[0] != want {
+		t.Fatalf("default path address mismatch: have %v %v, want %x", addresses, err, want)
+	}
+	if crypto.PubkeyToAddress(account.key.PrivateKey.PublicKey) != want {
+		t.Fatal("account key doesn't match its address")
+	}
+}
+
+func TestInvalidMnemonic(t *testing.T) {
+	if _, err := NewHDWallet("abandon abandon abandon", ""); err != ErrInvalidMnemonic {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrInvalidMnemonic)
+	}
+}
@@ -1,35 +1,211 @@
 package ethclient
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type KeyGenerator struct {
 	keyFolder string
+	scrypt    ScryptParams
+
+	wallet    *HDWallet
+	nextIndex int
+	indexMu   sync.Mutex
 }
 
 func NewKeyGenerator(folder string) *KeyGenerator {
-	return &KeyGenerator{folder}
+	return &KeyGenerator{
+		keyFolder: folder,
+		scrypt:    StandardScrypt,
+	}
+}
+
+// NewLightKeyGenerator uses light scrypt parameters which make key generation
+// and decryption fast, it's only for test accounts
+func NewLightKeyGenerator(folder string) *KeyGenerator {
+	kg := NewKeyGenerator(folder)
+	kg.SetScrypt(LightScrypt)
+	return kg
+}
+
+func (kg *KeyGenerator) SetScrypt(params ScryptParams) {
+	kg.scrypt = params
+}
+
+// SetSeed makes the generator derive keys from seed in sequence instead of
+// random, the same seed always generates the same accounts in the same order
+func (kg *KeyGenerator) SetSeed(seed string) error {
+	wallet, err := NewHDWalletFromSeed(crypto.Keccak256([]byte(seed)))
+	if err != nil {
+		return err
+	}
+
+	kg.indexMu.Lock()
+	kg.wallet = wallet
+	kg.nextIndex = 0
+	kg.indexMu.Unlock()
+	return nil
+}
+
+// reserve n indexes of seeded keys, together with the wallet they belong to
+func (kg *KeyGenerator) reserve(n int) (int, *HDWallet) {
+	kg.indexMu.Lock()
+	defer kg.indexMu.Unlock()
+	start := kg.nextIndex
+	kg.nextIndex += n
+	return start, kg.wallet
 }
 
 func (kg *KeyGenerator) GenerateKey(password string) (common.Address, error) {
-	ks := keystore.NewKeyStore(kg.keyFolder, keystore.StandardScryptN, keystore.StandardScryptP)
-	account, err := ks.NewAccount(password)
+	index, wallet := kg.reserve(1)
+	return kg.generateKey(password, wallet, index)
+}
+
+// generateKey derives the key at index of wallet, or a random key without
+// wallet
+func (kg *KeyGenerator) generateKey(password string, wallet *HDWallet, index int) (common.Address, error) {
+	var account *Account
+	var err error
+	if wallet != nil {
+		account, err = wallet.Derive(fmt.Sprintf("%s/%d", DefaultHDBasePath, index))
+	} else {
+		account, err = GenerateAccount()
+	}
 	if err != nil {
 		return common.Address{}, err
-	} else {
-		address := account.Address
-		if err := os.Rename(account.URL.Path, kg.keyFilePath(address.Hex())); err != nil {
-			panic("rename key file failed:" + err.Error())
+	}
+
+	return kg.ImportAccount(account, password)
+}
+
+// GenerateKeys generates n keys in parallel, seeded keys are still returned
+// in sequence
+func (kg *KeyGenerator) GenerateKeys(password string, n int) ([]common.Address, error) {
+	addresses := make([]common.Address, n)
+	errs := make([]error, n)
+	indexes := make(chan int)
+	start, wallet := kg.reserve(n)
+
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				addresses[i], errs[i] = kg.generateKey(password, wallet, start+i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
-		return address, nil
 	}
+	return addresses, nil
+}
+
+// ImportAccount saves the key of account encrypted with password
+func (kg *KeyGenerator) ImportAccount(account *Account, password string) (common.Address, error) {
+	account.SetPassword(password)
+	if err := account.ExportKeyFile(kg.keyFilePath(account.Address().Hex()), kg.scrypt); err != nil {
+		return common.Address{}, err
+	}
+	return account.Address(), nil
+}
+
+// ImportKeyFile copies a key file which can be decrypted by password
+func (kg *KeyGenerator) ImportKeyFile(keyFile, password string) (common.Address, error) {
+	json, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	key, err := keystore.DecryptKey(json, password)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if err := writeKeyFile(kg.keyFilePath(key.Address.Hex()), json); err != nil {
+		return common.Address{}, err
+	}
+	return key.Address, nil
+}
+
+func (kg *KeyGenerator) ExportKeyFile(address common.Address, keyFile string) error {
+	json, err := ioutil.ReadFile(kg.keyFilePath(address.Hex()))
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(keyFile, json)
+}
+
+// ChangePassword encrypts the key again with newPassword and the current
+// scrypt parameters
+func (kg *KeyGenerator) ChangePassword(address common.Address, password, newPassword string) error {
+	account, err := kg.GetAccount(address, password)
+	if err != nil {
+		return err
+	}
+
+	account.SetPassword(newPassword)
+	json, err := account.EncryptKey(kg.scrypt)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(kg.keyFilePath(address.Hex()), json)
+}
+
+// DeleteKey removes the key file, password is checked first
+func (kg *KeyGenerator) DeleteKey(address common.Address, password string) error {
+	if _, err := kg.GetAccount(address, password); err != nil {
+		return err
+	}
+	return os.Remove(kg.keyFilePath(address.Hex()))
+}
+
+// write to a temp file and rename, so an existing key file is never left
+// half written
+func writeKeyFile(keyFile string, json []byte) error {
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(keyFile), "."+filepath.Base(keyFile)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(json); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	f.Close()
+
+	if err := os.Chmod(f.Name(), 0600); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), keyFile); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
 }
 
 func (kg *KeyGenerator) keyFilePath(address string) string {
@@ -41,6 +217,13 @@ func (kg *KeyGenerator) GetAccount(address common.Address, password string) (*Ac
 	return NewAccount(kg.keyFilePath(address.Hex()), password)
 }
 
+// CopyKeyFile copies the key file of address into a keystore folder, like
+// the one of a geth node, and returns the new file path
+func (kg *KeyGenerator) CopyKeyFile(address common.Address, keystoreDir string) (string, error) {
+	keyFile := filepath.Join(keystoreDir, filepath.Base(kg.keyFilePath(address.Hex())))
+	return keyFile, kg.ExportKeyFile(address, keyFile)
+}
+
 func (kg *KeyGenerator) ListAddress() []common.Address {
 	keyFiles, err := ioutil.ReadDir(kg.keyFolder)
 	if err != nil {
@@ -0,0 +1,71 @@
+package ethclient
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestKeyGenerator(t *testing.T) *KeyGenerator {
+	folder, err := ioutil.TempDir("", "ethclient-keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(folder) })
+	return NewLightKeyGenerator(folder)
+}
+
+func TestSeededKeysAreReproducible(t *testing.T) {
+	var generated [2][]interface{}
+	for i := range generated {
+		kg := newTestKeyGenerator(t)
+		if err := kg.SetSeed("cluster"); err != nil {
+			t.Fatalf("set seed: %v", err)
+		}
+		first, err := kg.GenerateKey("")
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		rest, err := kg.GenerateKeys("", 4)
+		if err != nil {
+			t.Fatalf("generate keys: %v", err)
+		}
+		generated[i] = []interface{}{first, rest}
+	}
+	if !reflect.DeepEqual(generated[0], generated[1]) {
+		t.Fatalf("seeded keys differ: %v, %v", generated[0], generated[1])
+	}
+
+	// the keys follow the default path of the seed wallet
+	wallet, _ := NewHDWalletFromSeed(crypto.Keccak256([]byte("cluster")))
+	addresses, _ := wallet.Addresses(5)
+	if first := generated[0][0]; first != addresses[0] {
+		t.Fatalf("first key mismatch: have %x, want %x", first, addresses[0])
+	}
+	if !reflect.DeepEqual(generated[0][1], addresses[1:]) {
+		t.Fatalf("parallel keys out of order: have %x, want %x", generated[0][1], addresses[1:])
+	}
+}
+
+func TestSetSeedWhileGenerating(t *testing.T) {
+	kg := newTestKeyGenerator(t)
+	kg.SetSeed("first")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if _, err := kg.GenerateKeys("", 4); err != nil {
+			t.Errorf("generate keys: %v", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		kg.SetSeed("second")
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,74 @@
+package ethclient
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const signatureLength = 65
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Ecrecover returns the address which signs hash with the [R || S || V]
+// signature, V is 0 or 1
+func Ecrecover(hash, signature []byte) (common.Address, error) {
+	pubkey, err := crypto.Ecrecover(hash, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	return signer, nil
+}
+
+// MessageHash is the eip191 hash of msg, which is used by personal_sign
+func MessageHash(msg []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(msg))
+	return crypto.Keccak256([]byte(prefix), msg)
+}
+
+// SignMessage signs msg with the eip191 prefix, V of the signature is 27 or
+// 28 like personal_sign
+func SignMessage(signer Signer, msg []byte) ([]byte, error) {
+	return signHash(signer, MessageHash(msg))
+}
+
+func RecoverMessage(msg, signature []byte) (common.Address, error) {
+	return recoverHash(MessageHash(msg), signature)
+}
+
+func VerifyMessage(address common.Address, msg, signature []byte) bool {
+	signer, err := RecoverMessage(msg, signature)
+	return err == nil && signer == address
+}
+
+func signHash(signer Signer, hash []byte) ([]byte, error) {
+	signature, err := signer.SignHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	signature[signatureLength-1] += 27
+	return signature, nil
+}
+
+// recoverHash accepts V as 0, 1 or 27, 28
+func recoverHash(hash, signature []byte) (common.Address, error) {
+	if len(signature) != signatureLength {
+		return common.Address{}, ErrInvalidSignature
+	}
+
+	sig := make([]byte, signatureLength)
+	copy(sig, signature)
+	if sig[signatureLength-1] >= 27 {
+		sig[signatureLength-1] -= 27
+	}
+	if sig[signatureLength-1] > 1 {
+		return common.Address{}, ErrInvalidSignature
+	}
+	return Ecrecover(hash, sig)
+}
@@ -0,0 +1,42 @@
+package ethclient
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestMessageHash(t *testing.T) {
+	// the vector of TextHash in go-ethereum
+	want := "0xa080337ae51c4e064c189e113edd0ba391df9206e2f49db658bb32cf2911730b"
+	if got := hexutil.Encode(MessageHash([]byte("Hello Joe"))); got != want {
+		t.Fatalf("hash mismatch: have %s, want %s", got, want)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	key, _ := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+	signer := NewAccountFromKey(key)
+	msg := []byte("Hello Joe")
+
+	signature, err := SignMessage(signer, msg)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if v := signature[signatureLength-1]; v != 27 && v != 28 {
+		t.Fatalf("v of signature is %d", v)
+	}
+	if !VerifyMessage(signer.Address(), msg, signature) {
+		t.Fatal("signature not verified")
+	}
+
+	// V as 0 or 1 is accepted too
+	signature[signatureLength-1] -= 27
+	if recovered, err := RecoverMessage(msg, signature); err != nil || recovered != signer.Address() {
+		t.Fatalf("recovered signer mismatch: have %x %v, want %x", recovered, err, signer.Address())
+	}
+	if VerifyMessage(signer.Address(), []byte("Hello Bob"), signature) {
+		t.Fatal("signature verified for another message")
+	}
+}
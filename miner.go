@@ -1,39 +1,113 @@
 package ethclient
 
 import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 func (ec *Client) Coinbase() (common.Address, error) {
+	return ec.CoinbaseContext(context.Background())
+}
+
+func (ec *Client) CoinbaseContext(ctx context.Context) (common.Address, error) {
 	var address common.Address
-	err := ec.c.Call(&address, "eth_coinbase")
+	err := ec.call(ctx, &address, "eth_coinbase")
 	return address, err
 }
 
-func (ec *Client) SetCoinbase(account *Account) (bool, error) {
-	var address common.Address
-	err := ec.c.Call(&address, "personal_importRawKey", account.PrivateKey(), account.Password())
+var (
+	ErrKeyNotInstallable = errors.New("signer can't install its key into node")
+)
+
+// KeyFileInstaller is a signer which can write its key file into the
+// keystore of a node, like Account. Password decrypts the key file, it's
+// meant for the password file given to the node with --password
+type KeyFileInstaller interface {
+	Signer
+	InstallKeyFile(keystoreDir string) (string, error)
+	Password() string
+}
+
+// SetCoinbase writes the key file of signer into the keystore of the node
+// and makes it the etherbase. The key file is copied through the file system,
+// so it only works when the node runs on the same machine. Neither the key
+// nor the password goes over rpc, the node should be restarted with
+// --unlock and --password to seal with the account
+func (ec *Client) SetCoinbase(signer Signer) (bool, error) {
+	return ec.SetCoinbaseContext(context.Background(), signer)
+}
+
+func (ec *Client) SetCoinbaseContext(ctx context.Context, signer Signer) (bool, error) {
+	installer, ok := signer.(KeyFileInstaller)
+	if ok == false {
+		return false, ErrKeyNotInstallable
+	}
+
+	datadir, err := ec.DatadirContext(ctx)
 	if err != nil {
 		return false, err
 	}
 
-	var succeed bool
-	err = ec.c.Call(&succeed, "personal_unlockAccount", account.Address(), account.Password())
-	if err != nil {
-		return succeed, err
+	if _, err := installer.InstallKeyFile(filepath.Join(datadir, "keystore")); err != nil {
+		return false, err
 	}
 
-	succeed = false
-	err = ec.c.Call(&succeed, "miner_setEtherbase", account.Address())
-	return succeed, err
+	return ec.SetEtherbaseContext(ctx, installer.Address())
+}
+
+func (ec *Client) SetEtherbase(address common.Address) (bool, error) {
+	return ec.SetEtherbaseContext(context.Background(), address)
+}
+
+func (ec *Client) SetEtherbaseContext(ctx context.Context, address common.Address) (bool, error) {
+	return ec.callBool(ctx, "miner_setEtherbase", address)
+}
+
+func (ec *Client) SetExtra(extra string) (bool, error) {
+	return ec.SetExtraContext(context.Background(), extra)
+}
+
+func (ec *Client) SetExtraContext(ctx context.Context, extra string) (bool, error) {
+	return ec.callBool(ctx, "miner_setExtra", extra)
+}
+
+// SetMinerGasPrice sets the minimal gas price of transactions the miner
+// accepts
+func (ec *Client) SetMinerGasPrice(gasPrice *big.Int) (bool, error) {
+	return ec.SetMinerGasPriceContext(context.Background(), gasPrice)
+}
+
+func (ec *Client) SetMinerGasPriceContext(ctx context.Context, gasPrice *big.Int) (bool, error) {
+	return ec.callBool(ctx, "miner_setGasPrice", (*hexutil.Big)(gasPrice))
+}
+
+func (ec *Client) SetMinerGasLimit(gasLimit uint64) (bool, error) {
+	return ec.SetMinerGasLimitContext(context.Background(), gasLimit)
+}
+
+func (ec *Client) SetMinerGasLimitContext(ctx context.Context, gasLimit uint64) (bool, error) {
+	return ec.callBool(ctx, "miner_setGasLimit", hexutil.Uint64(gasLimit))
 }
 
 func (ec *Client) MinerStart(threadCount int) error {
-	return ec.c.Call(nil, "miner_start", threadCount)
+	return ec.MinerStartContext(context.Background(), threadCount)
+}
+
+func (ec *Client) MinerStartContext(ctx context.Context, threadCount int) error {
+	return ec.call(ctx, nil, "miner_start", threadCount)
 }
 
 func (ec *Client) MinerStop() (bool, error) {
+	return ec.MinerStopContext(context.Background())
+}
+
+func (ec *Client) MinerStopContext(ctx context.Context) (bool, error) {
 	var succeed bool
-	err := ec.c.Call(&succeed, "miner_stop")
+	err := ec.call(ctx, &succeed, "miner_stop")
 	return succeed, err
 }
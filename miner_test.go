@@ -0,0 +1,53 @@
+package ethclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type AdminService struct{ datadir string }
+
+func (s *AdminService) Datadir() string { return s.datadir }
+
+type MinerService struct{ etherbase common.Address }
+
+func (s *MinerService) SetEtherbase(address common.Address) bool {
+	s.etherbase = address
+	return true
+}
+
+func TestSetCoinbase(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "ethclient-miner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(datadir)
+
+	// no personal namespace, unlocking over rpc would fail
+	miner := &MinerService{}
+	client := newTestClient(t, map[string]interface{}{
+		"admin": &AdminService{datadir},
+		"miner": miner,
+	})
+
+	account, err := GenerateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account.SetPassword("secret")
+	if succeed, err := client.SetCoinbase(account); err != nil || !succeed {
+		t.Fatalf("set coinbase: %v %v", succeed, err)
+	}
+	if miner.etherbase != account.Address() {
+		t.Fatalf("etherbase mismatch: have %x, want %x", miner.etherbase, account.Address())
+	}
+
+	keyFile := filepath.Join(datadir, "keystore", account.Address().Hex()+".key")
+	if _, err := NewAccount(keyFile, "secret"); err != nil {
+		t.Fatalf("installed key file: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package ethclient
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type NonceReader interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+type accountNonce struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64
+	released []uint64
+}
+
+// NonceManager hands out nonces for each account locally, so concurrent
+// senders from one account don't reuse the same nonce. The pending nonce
+// from the node is only read at the first use or after a reset
+type NonceManager struct {
+	reader   NonceReader
+	accounts map[common.Address]*accountNonce
+	mu       sync.Mutex
+}
+
+func NewNonceManager(reader NonceReader) *NonceManager {
+	return &NonceManager{
+		reader:   reader,
+		accounts: make(map[common.Address]*accountNonce),
+	}
+}
+
+func (nm *NonceManager) account(address common.Address) *accountNonce {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	an, ok := nm.accounts[address]
+	if ok == false {
+		an = &accountNonce{}
+		nm.accounts[address] = an
+	}
+	return an
+}
+
+// Next reserves a nonce for address, the nonce should be given back with
+// Release or Failed if the transaction isn't sent
+func (nm *NonceManager) Next(ctx context.Context, address common.Address) (uint64, error) {
+	an := nm.account(address)
+	an.mu.Lock()
+	defer an.mu.Unlock()
+
+	if an.synced == false {
+		nonce, err := nm.reader.PendingNonceAt(ctx, address)
+		if err != nil {
+			return 0, err
+		}
+		an.next = nonce
+		an.released = nil
+		an.synced = true
+	}
+
+	if len(an.released) > 0 {
+		nonce := an.released[0]
+		an.released = an.released[1:]
+		return nonce, nil
+	}
+
+	nonce := an.next
+	an.next += 1
+	return nonce, nil
+}
+
+// Release gives back a reserved nonce which is never used
+func (nm *NonceManager) Release(address common.Address, nonce uint64) {
+	an := nm.account(address)
+	an.mu.Lock()
+	defer an.mu.Unlock()
+
+	if an.synced == false || nonce >= an.next {
+		return
+	}
+
+	if nonce+1 == an.next {
+		an.next = nonce
+		// released nonces at the tail are no longer gaps
+		for len(an.released) > 0 && an.released[len(an.released)-1]+1 == an.next {
+			an.next = an.released[len(an.released)-1]
+			an.released = an.released[:len(an.released)-1]
+		}
+		return
+	}
+
+	i := sort.Search(len(an.released), func(i int) bool { return an.released[i] >= nonce })
+	if i < len(an.released) && an.released[i] == nonce {
+		return
+	}
+	an.released = append(an.released, 0)
+	copy(an.released[i+1:], an.released[i:])
+	an.released[i] = nonce
+}
+
+// Reset drops the local state of address, next call to Next will resync
+// with the node
+func (nm *NonceManager) Reset(address common.Address) {
+	an := nm.account(address)
+	an.mu.Lock()
+	an.synced = false
+	an.released = nil
+	an.mu.Unlock()
+}
+
+// Failed should be called when sending a transaction with nonce returns err.
+// The nonce is only released when the node answered with an error, which
+// proves the transaction was rejected. Nonce errors, timeouts and transport
+// errors leave the transaction possibly pooled, so the account resyncs
+func (nm *NonceManager) Failed(address common.Address, nonce uint64, err error) {
+	if isRejected(err) && IsNonceError(err) == false {
+		nm.Release(address, nonce)
+	} else {
+		nm.Reset(address)
+	}
+}
+
+// isRejected reports whether err is an error response of the node
+func isRejected(err error) bool {
+	_, ok := err.(rpc.Error)
+	return ok
+}
+
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "nonce too high") ||
+		strings.Contains(msg, "known transaction") ||
+		strings.Contains(msg, "replacement transaction underpriced")
+}
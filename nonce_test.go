@@ -0,0 +1,96 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type fakeNonceReader struct {
+	nonce uint64
+	reads int
+}
+
+func (r *fakeNonceReader) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	r.reads += 1
+	return r.nonce, nil
+}
+
+type fakeRPCError struct{ msg string }
+
+func (e *fakeRPCError) Error() string  { return e.msg }
+func (e *fakeRPCError) ErrorCode() int { return -32000 }
+
+func nextNonce(t *testing.T, nm *NonceManager, address common.Address) uint64 {
+	nonce, err := nm.Next(context.Background(), address)
+	if err != nil {
+		t.Fatalf("next nonce: %v", err)
+	}
+	return nonce
+}
+
+func TestNonceManagerNext(t *testing.T) {
+	reader := &fakeNonceReader{nonce: 5}
+	nm := NewNonceManager(reader)
+	address := common.HexToAddress("0x01")
+
+	for want := uint64(5); want < 8; want++ {
+		if got := nextNonce(t, nm, address); got != want {
+			t.Fatalf("nonce mismatch: have %d, want %d", got, want)
+		}
+	}
+	if reader.reads != 1 {
+		t.Fatalf("pending nonce read %d times, want 1", reader.reads)
+	}
+}
+
+func TestNonceManagerRelease(t *testing.T) {
+	nm := NewNonceManager(&fakeNonceReader{})
+	address := common.HexToAddress("0x01")
+
+	for i := 0; i < 4; i++ {
+		nextNonce(t, nm, address)
+	}
+	// a gap is handed out again before new nonces
+	nm.Release(address, 1)
+	if got := nextNonce(t, nm, address); got != 1 {
+		t.Fatalf("released nonce not reused: have %d, want 1", got)
+	}
+	// releasing the tail also drops the released gaps below it
+	nm.Release(address, 2)
+	nm.Release(address, 3)
+	if got := nextNonce(t, nm, address); got != 2 {
+		t.Fatalf("tail release: have %d, want 2", got)
+	}
+}
+
+func TestNonceManagerFailed(t *testing.T) {
+	tests := []struct {
+		err    error
+		resync bool
+	}{
+		{&fakeRPCError{"insufficient funds for gas * price + value"}, false},
+		{&fakeRPCError{"nonce too low"}, true},
+		{context.DeadlineExceeded, true},
+		{errors.New("EOF"), true},
+	}
+	address := common.HexToAddress("0x01")
+	for i, test := range tests {
+		reader := &fakeNonceReader{nonce: 10}
+		nm := NewNonceManager(reader)
+		nonce := nextNonce(t, nm, address)
+
+		reader.nonce = 11
+		nm.Failed(address, nonce, test.err)
+		got := nextNonce(t, nm, address)
+		if test.resync {
+			if reader.reads != 2 || got != 11 {
+				t.Errorf("test %d: expected resync, have nonce %d after %d reads", i, got, reader.reads)
+			}
+		} else if reader.reads != 1 || got != 10 {
+			t.Errorf("test %d: expected release, have nonce %d after %d reads", i, got, reader.reads)
+		}
+	}
+}
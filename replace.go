@@ -0,0 +1,154 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// minimal gas price bump in percent the txpool accepts for a replacement
+const DefaultPriceBump = 10
+
+// gas used by a plain transfer
+const transferGas = 21000
+
+var (
+	ErrAlreadyMined            = errors.New("transaction is already mined")
+	ErrNotSender               = errors.New("account isn't the sender of transaction")
+	ErrReplacementUnderpriced  = errors.New("replacement gas price is too low")
+	ErrNoReplacementMined      = errors.New("neither transaction is mined")
+	ErrTransactionsNotReplaced = errors.New("transactions have different nonce")
+)
+
+// MinReplacementGasPrice returns the lowest gas price which can replace tx
+func MinReplacementGasPrice(tx *types.Transaction) *big.Int {
+	price := new(big.Int).Mul(tx.GasPrice(), big.NewInt(100+DefaultPriceBump))
+	price.Add(price, big.NewInt(99))
+	return price.Div(price, big.NewInt(100))
+}
+
+// SpeedUp resends tx with the same nonce and a higher gas price, nil
+// gasPrice uses the minimal price the txpool accepts. Replacing needs a node
+// but Account has no client, so SpeedUp and Cancel are on Client and take any
+// Signer, *Account included
+func (c *Client) SpeedUp(ctx context.Context, from Signer, tx *types.Transaction, gasPrice *big.Int) (*types.Transaction, error) {
+	return c.replace(ctx, from, tx, gasPrice, func(price *big.Int) *types.Transaction {
+		if tx.To() == nil {
+			return types.NewContractCreation(tx.Nonce(), tx.Value(), tx.Gas(), price, tx.Data())
+		}
+		return types.NewTransaction(tx.Nonce(), *tx.To(), tx.Value(), tx.Gas(), price, tx.Data())
+	})
+}
+
+// Cancel replaces tx with a zero value transfer to the sender itself
+func (c *Client) Cancel(ctx context.Context, from Signer, tx *types.Transaction, gasPrice *big.Int) (*types.Transaction, error) {
+	return c.replace(ctx, from, tx, gasPrice, func(price *big.Int) *types.Transaction {
+		return types.NewTransaction(tx.Nonce(), from.Address(), big.NewInt(0), big.NewInt(transferGas), price, nil)
+	})
+}
+
+func (c *Client) replace(ctx context.Context, from Signer, tx *types.Transaction, gasPrice *big.Int, build func(*big.Int) *types.Transaction) (*types.Transaction, error) {
+	signer, err := c.Signer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	} else if sender != from.Address() {
+		return nil, ErrNotSender
+	}
+
+	if _, isPending, err := c.TransactionByHashContext(ctx, tx.Hash()); err != nil && err != ethereum.NotFound {
+		return nil, err
+	} else if err == nil && isPending == false {
+		return nil, ErrAlreadyMined
+	}
+
+	minPrice := MinReplacementGasPrice(tx)
+	if gasPrice == nil {
+		gasPrice = minPrice
+	} else if gasPrice.Cmp(minPrice) < 0 {
+		return nil, fmt.Errorf("%s: %v is less than %v", ErrReplacementUnderpriced.Error(), gasPrice, minPrice)
+	}
+
+	replacement := build(gasPrice)
+	if err := c.checkFee(replacement); err != nil {
+		return nil, err
+	}
+
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := signTx(ctx, from, chainID, replacement)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.SendTransaction(ctx, signedTx); err != nil {
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// WaitReplaced waits until the original transaction or one of its
+// replacements is mined, and returns the mined one with its receipt
+func (c *Client) WaitReplaced(ctx context.Context, original *types.Transaction, replacements ...*types.Transaction) (*types.Transaction, *MinedReceipt, error) {
+	txs := append([]*types.Transaction{original}, replacements...)
+	for _, tx := range replacements {
+		if tx.Nonce() != original.Nonce() {
+			return nil, nil, ErrTransactionsNotReplaced
+		}
+	}
+
+	signer, err := c.Signer(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sender, err := types.Sender(signer, original)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	waiter := c.newHeadWaiter(ctx)
+	defer waiter.Close()
+
+	for {
+		if tx, receipt, err := c.findMined(ctx, txs); err != nil || tx != nil {
+			return tx, receipt, err
+		}
+
+		nonce, err := c.NonceAtContext(ctx, sender, nil)
+		if err != nil {
+			return nil, nil, err
+		} else if nonce > original.Nonce() {
+			// a block may arrive between the receipt and nonce query
+			if tx, receipt, err := c.findMined(ctx, txs); err != nil || tx != nil {
+				return tx, receipt, err
+			}
+			return nil, nil, ErrNoReplacementMined
+		}
+
+		if err := waiter.Wait(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func (c *Client) findMined(ctx context.Context, txs []*types.Transaction) (*types.Transaction, *MinedReceipt, error) {
+	for _, tx := range txs {
+		receipt, err := c.minedReceipt(ctx, tx.Hash())
+		if err == nil {
+			return tx, receipt, nil
+		} else if err != ethereum.NotFound {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, nil
+}
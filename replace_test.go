@@ -0,0 +1,203 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestMinReplacementGasPrice(t *testing.T) {
+	tests := []struct {
+		price, min int64
+	}{
+		{0, 0},
+		{1, 2},
+		{10, 11},
+		{11, 13},
+		{20, 22},
+		{100, 110},
+		{101, 112},
+		{1000000000, 1100000000},
+	}
+	for _, test := range tests {
+		tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), big.NewInt(transferGas), big.NewInt(test.price), nil)
+		if got := MinReplacementGasPrice(tx); got.Int64() != test.min {
+			t.Errorf("price %d: have %v, want %d", test.price, got, test.min)
+		}
+	}
+}
+
+// ReplaceService is a node with a pool of pending transactions, mined ones
+// have a receipt
+type ReplaceService struct {
+	chainID *big.Int
+	txs     map[common.Hash]*types.Transaction
+	mined   map[common.Hash]bool
+	nonce   uint64
+	sent    []*types.Transaction
+}
+
+func newReplaceService(txs ...*types.Transaction) *ReplaceService {
+	s := &ReplaceService{
+		chainID: big.NewInt(DefaultChainID),
+		txs:     make(map[common.Hash]*types.Transaction),
+		mined:   make(map[common.Hash]bool),
+	}
+	for _, tx := range txs {
+		s.txs[tx.Hash()] = tx
+	}
+	return s
+}
+
+func (s *ReplaceService) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(s.chainID)
+}
+
+func (s *ReplaceService) GetTransactionByHash(hash common.Hash) (map[string]interface{}, error) {
+	tx, ok := s.txs[hash]
+	if ok == false {
+		return nil, nil
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if s.mined[hash] {
+		fields["blockHash"] = common.Hash{1}
+		fields["blockNumber"] = "0x1"
+	}
+	return fields, nil
+}
+
+func (s *ReplaceService) GetTransactionReceipt(hash common.Hash) map[string]interface{} {
+	if s.mined[hash] == false {
+		return nil
+	}
+	return map[string]interface{}{
+		"status":            "0x1",
+		"cumulativeGasUsed": "0x5208",
+		"gasUsed":           "0x5208",
+		"logsBloom":         hexutil.Encode(make([]byte, types.BloomByteLength)),
+		"logs":              []interface{}{},
+		"transactionHash":   hash,
+		"blockHash":         common.Hash{1},
+		"blockNumber":       "0x1",
+	}
+}
+
+func (s *ReplaceService) GetTransactionCount(address common.Address, block string) hexutil.Uint64 {
+	return hexutil.Uint64(s.nonce)
+}
+
+func (s *ReplaceService) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		return common.Hash{}, err
+	}
+	s.txs[tx.Hash()] = tx
+	s.sent = append(s.sent, tx)
+	return tx.Hash(), nil
+}
+
+func signedTestTx(t *testing.T, account *Account, nonce uint64, gasPrice int64) *types.Transaction {
+	tx := types.NewTransaction(nonce, common.HexToAddress("0x01"), big.NewInt(5), big.NewInt(transferGas), big.NewInt(gasPrice), []byte{1})
+	signedTx, err := account.SignTx(big.NewInt(DefaultChainID), tx)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return signedTx
+}
+
+func TestSpeedUpAndCancel(t *testing.T) {
+	account, _ := GenerateAccount()
+	other, _ := GenerateAccount()
+	original := signedTestTx(t, account, 3, 10)
+	ctx := context.Background()
+
+	service := newReplaceService(original)
+	client := newTestClient(t, map[string]interface{}{"eth": service})
+
+	spedUp, err := client.SpeedUp(ctx, account, original, nil)
+	if err != nil {
+		t.Fatalf("speed up: %v", err)
+	}
+	if spedUp.Nonce() != 3 || spedUp.GasPrice().Int64() != 11 || *spedUp.To() != *original.To() ||
+		spedUp.Value().Cmp(original.Value()) != 0 || string(spedUp.Data()) != string(original.Data()) {
+		t.Errorf("speed up mismatch: nonce %d, price %v", spedUp.Nonce(), spedUp.GasPrice())
+	}
+
+	cancel, err := client.Cancel(ctx, account, original, big.NewInt(20))
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if cancel.Nonce() != 3 || cancel.GasPrice().Int64() != 20 || *cancel.To() != account.Address() ||
+		cancel.Value().Sign() != 0 || len(cancel.Data()) != 0 {
+		t.Errorf("cancel mismatch: nonce %d, to %x, value %v", cancel.Nonce(), cancel.To(), cancel.Value())
+	}
+	if len(service.sent) != 2 || service.sent[0].Hash() != spedUp.Hash() || service.sent[1].Hash() != cancel.Hash() {
+		t.Errorf("sent transactions mismatch: %d", len(service.sent))
+	}
+
+	tests := []struct {
+		from     *Account
+		gasPrice *big.Int
+		mined    bool
+		err      error
+	}{
+		{account, big.NewInt(10), false, ErrReplacementUnderpriced},
+		{account, big.NewInt(10), true, ErrAlreadyMined},
+		{other, nil, false, ErrNotSender},
+	}
+	for i, test := range tests {
+		service := newReplaceService(original)
+		service.mined[original.Hash()] = test.mined
+		client := newTestClient(t, map[string]interface{}{"eth": service})
+		if _, err := client.SpeedUp(ctx, test.from, original, test.gasPrice); err == nil || !containsError(err, test.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+		if len(service.sent) != 0 {
+			t.Errorf("test %d: replacement is sent", i)
+		}
+	}
+}
+
+func TestWaitReplaced(t *testing.T) {
+	account, _ := GenerateAccount()
+	original := signedTestTx(t, account, 3, 10)
+	spedUp := signedTestTx(t, account, 3, 11)
+	cancel := signedTestTx(t, account, 3, 13)
+	ctx := context.Background()
+
+	service := newReplaceService(original, spedUp, cancel)
+	service.mined[spedUp.Hash()] = true
+	service.nonce = 4
+	client := newTestClient(t, map[string]interface{}{"eth": service})
+
+	tx, receipt, err := client.WaitReplaced(ctx, original, spedUp, cancel)
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if tx.Hash() != spedUp.Hash() || receipt.TxHash != spedUp.Hash() {
+		t.Errorf("mined transaction mismatch: have %x, want %x", tx.Hash(), spedUp.Hash())
+	}
+
+	// the nonce is used by another transaction
+	service.mined[spedUp.Hash()] = false
+	if _, _, err := client.WaitReplaced(ctx, original, spedUp, cancel); err != ErrNoReplacementMined {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrNoReplacementMined)
+	}
+
+	if _, _, err := client.WaitReplaced(ctx, original, signedTestTx(t, account, 4, 11)); err != ErrTransactionsNotReplaced {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrTransactionsNotReplaced)
+	}
+}
@@ -0,0 +1,148 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// error of a reverted call or frame, old nodes prefix it with "evm: "
+const executionReverted = "execution reverted"
+
+var (
+	ErrTransactionSucceeded = errors.New("transaction didn't fail")
+	ErrNoRevertReason       = errors.New("transaction failed without reverting")
+
+	// selector of Error(string)
+	revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+)
+
+// RevertError is returned when a call reverts, reason is empty for revert(),
+// require without message and errors other than Error(string)
+type RevertError struct {
+	Reason string
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason == "" {
+		return "execution reverted"
+	}
+	return "execution reverted: " + e.Reason
+}
+
+// UnpackRevert decodes the reason of Error(string) revert data, normal
+// outputs are multiple of 32 bytes so they are never taken as revert data
+func UnpackRevert(data []byte) (string, bool) {
+	if len(data) < len(revertSelector)+32 || len(data)%32 != len(revertSelector) ||
+		bytes.Equal(data[:len(revertSelector)], revertSelector) == false {
+		return "", false
+	}
+
+	stringType, err := abi.NewType("string")
+	if err != nil {
+		return "", false
+	}
+	values, err := unpackArguments([]abi.Argument{{Type: stringType}}, data[len(revertSelector):])
+	if err != nil {
+		return "", false
+	}
+	return values[0].(string), true
+}
+
+// nodes after geth 1.9 return the revert data as error data instead of the
+// call result
+type rpcDataError interface {
+	ErrorData() interface{}
+}
+
+// revertFromRPCError turns the error of a reverted call into *RevertError,
+// the reason is decoded from the error data, or taken from the message like
+// "execution reverted: reason". Nodes before geth 1.9 don't fail reverted
+// calls, their result is the revert data, or empty which can't be told from
+// a call without output
+func revertFromRPCError(err error) error {
+	msg := err.Error()
+	i := strings.Index(msg, executionReverted)
+	if i < 0 {
+		return err
+	}
+	revertErr := &RevertError{Reason: strings.TrimPrefix(msg[i+len(executionReverted):], ": ")}
+	if dataErr, ok := err.(rpcDataError); ok {
+		if hex, ok := dataErr.ErrorData().(string); ok {
+			if data, err := hexutil.Decode(hex); err == nil {
+				if reason, ok := UnpackRevert(data); ok {
+					revertErr.Reason = reason
+				}
+			}
+		}
+	}
+	return revertErr
+}
+
+// TransactionRevertReason replays a failed transaction with eth_call from
+// its sender on the state before its block. The reason is empty if the
+// transaction reverts without one. The replay doesn't see the transactions
+// before it in the same block, so if it doesn't revert the transaction is
+// traced with callTracer where the node supports it, else ErrNoRevertReason
+// is returned. It's also returned if the transaction fails otherwise, like
+// running out of gas
+func (ec *Client) TransactionRevertReason(ctx context.Context, txHash common.Hash) (string, error) {
+	receipt, err := ec.minedReceipt(ctx, txHash)
+	if err != nil {
+		return "", err
+	} else if receipt.Status == types.ReceiptStatusSuccessful {
+		return "", ErrTransactionSucceeded
+	}
+
+	tx, _, err := ec.TransactionByHashContext(ctx, txHash)
+	if err != nil {
+		return "", err
+	}
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return "", err
+	}
+
+	msg := ethereum.CallMsg{
+		From:     from,
+		To:       tx.To(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}
+	parent := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	_, callErr := ec.CallContract(ctx, msg, parent)
+	if revertErr, ok := callErr.(*RevertError); ok && revertErr.Reason != "" {
+		return revertErr.Reason, nil
+	}
+
+	// debug api is optional, nodes before geth 1.8 have no callTracer
+	frame, err := ec.TraceTransactionCallsWithOpts(ctx, txHash, &CallTracerConfig{OnlyTopCall: true})
+	if err == nil {
+		if strings.HasSuffix(frame.Error, executionReverted) == false {
+			return "", fmt.Errorf("%s: %s", ErrNoRevertReason.Error(), frame.Error)
+		}
+		return frame.Reason(), nil
+	}
+
+	if _, ok := callErr.(*RevertError); ok {
+		return "", nil
+	} else if callErr != nil {
+		return "", fmt.Errorf("%s: %s", ErrNoRevertReason.Error(), callErr.Error())
+	}
+	return "", ErrNoRevertReason
+}
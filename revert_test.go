@@ -0,0 +1,191 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// revertData encodes Error(reason)
+func revertData(reason string) []byte {
+	data := append([]byte{}, revertSelector...)
+	data = append(data, math.PaddedBigBytes(big.NewInt(32), 32)...)
+	data = append(data, math.PaddedBigBytes(big.NewInt(int64(len(reason))), 32)...)
+	padded := (len(reason) + 31) / 32 * 32
+	return append(data, common.RightPadBytes([]byte(reason), padded)...)
+}
+
+func TestUnpackRevert(t *testing.T) {
+	tests := []struct {
+		data   []byte
+		reason string
+		ok     bool
+	}{
+		{revertData("Not enough Ether provided."), "Not enough Ether provided.", true},
+		{revertData(""), "", true},
+		{nil, "", false},
+		{revertSelector, "", false},
+		{math.PaddedBigBytes(big.NewInt(1), 32), "", false},
+		{append([]byte{0, 0, 0, 0}, revertData("x")[4:]...), "", false},
+	}
+	for i, test := range tests {
+		reason, ok := UnpackRevert(test.data)
+		if reason != test.reason || ok != test.ok {
+			t.Errorf("test %d: have %q %v, want %q %v", i, reason, ok, test.reason, test.ok)
+		}
+	}
+}
+
+type fakeDataError struct {
+	msg  string
+	data interface{}
+}
+
+func (e *fakeDataError) Error() string          { return e.msg }
+func (e *fakeDataError) ErrorData() interface{} { return e.data }
+
+func TestRevertFromRPCError(t *testing.T) {
+	tests := []struct {
+		err    error
+		revert bool
+		reason string
+	}{
+		{errors.New("execution reverted"), true, ""},
+		{errors.New("execution reverted: nope"), true, "nope"},
+		{&fakeDataError{"execution reverted", hexutil.Encode(revertData("from data"))}, true, "from data"},
+		{&fakeDataError{"execution reverted", "0x12345678"}, true, ""},
+		{errors.New("insufficient funds for gas * price + value"), false, ""},
+	}
+	for i, test := range tests {
+		err := revertFromRPCError(test.err)
+		revertErr, ok := err.(*RevertError)
+		if ok != test.revert {
+			t.Errorf("test %d: have %v, want revert %v", i, err, test.revert)
+		} else if ok && revertErr.Reason != test.reason {
+			t.Errorf("test %d: reason mismatch: have %q, want %q", i, revertErr.Reason, test.reason)
+		}
+	}
+}
+
+// RevertService answers eth calls with a revert and serves a failed
+// transaction, with its trace if frame is set
+type RevertService struct {
+	callErr error
+	result  hexutil.Bytes
+	frame   map[string]interface{}
+	tx      *types.Transaction
+
+	callArgs  map[string]interface{}
+	callBlock string
+}
+
+func (s *RevertService) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	s.callArgs, s.callBlock = args, block
+	return s.result, s.callErr
+}
+
+func (s *RevertService) GetTransactionByHash(hash common.Hash) *types.Transaction {
+	return s.tx
+}
+
+func (s *RevertService) GetTransactionReceipt(hash common.Hash) map[string]interface{} {
+	return map[string]interface{}{
+		"status":            "0x0",
+		"cumulativeGasUsed": "0x5208",
+		"gasUsed":           "0x5208",
+		"logsBloom":         hexutil.Encode(make([]byte, types.BloomByteLength)),
+		"logs":              []interface{}{},
+		"transactionHash":   hash,
+		"blockHash":         common.Hash{1},
+		"blockNumber":       "0x2",
+	}
+}
+
+// TraceTransaction fails like geth 1.7, which has no callTracer, unless
+// frame is set
+func (s *RevertService) TraceTransaction(hash common.Hash, config *TraceConfig) (map[string]interface{}, error) {
+	if config == nil || config.Tracer != callTracer {
+		return nil, errors.New("unexpected tracer")
+	} else if s.frame == nil {
+		return nil, errors.New("ReferenceError: 'callTracer' is not defined")
+	}
+	return s.frame, nil
+}
+
+func TestCallContractRevert(t *testing.T) {
+	tests := []struct {
+		service *RevertService
+		reason  string
+	}{
+		{&RevertService{callErr: errors.New("execution reverted")}, ""},
+		{&RevertService{callErr: errors.New("execution reverted: nope")}, "nope"},
+		{&RevertService{result: revertData("old node")}, "old node"},
+	}
+	for i, test := range tests {
+		client := newTestClient(t, map[string]interface{}{"eth": test.service})
+		_, err := client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+		if revertErr, ok := err.(*RevertError); !ok || revertErr.Reason != test.reason {
+			t.Errorf("test %d: have %v, want reason %q", i, err, test.reason)
+		}
+	}
+}
+
+func TestTransactionRevertReason(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.Address{3}
+	tx, err := types.SignTx(types.NewTransaction(7, to, big.NewInt(1), big.NewInt(50000), big.NewInt(1), []byte{0xd0, 0x9d}), types.NewEIP155Signer(big.NewInt(5)), key)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	tests := []struct {
+		callErr error
+		result  hexutil.Bytes
+		frame   map[string]interface{}
+		reason  string
+		err     error
+	}{
+		// replayed by eth_call, geth 1.7 returns the revert data as result
+		{nil, revertData("old node"), nil, "old node", nil},
+		{errors.New("execution reverted: nope"), nil, nil, "nope", nil},
+		{errors.New("execution reverted"), nil, nil, "", nil},
+		// replay doesn't revert, nothing else to try without callTracer
+		{nil, nil, nil, "", ErrNoRevertReason},
+		{errors.New("out of gas"), nil, nil, "", ErrNoRevertReason},
+		// the trace sees the transactions before it in the block
+		{nil, nil, map[string]interface{}{"error": "execution reverted", "revertReason": "traced"}, "traced", nil},
+		{errors.New("execution reverted"), nil, map[string]interface{}{"error": "execution reverted", "output": hexutil.Encode(revertData("from output"))}, "from output", nil},
+		{nil, nil, map[string]interface{}{"error": "evm: execution reverted"}, "", nil},
+		{nil, nil, map[string]interface{}{"error": "out of gas"}, "", ErrNoRevertReason},
+	}
+	for i, test := range tests {
+		service := &RevertService{callErr: test.callErr, result: test.result, frame: test.frame, tx: tx}
+		client := newTestClient(t, map[string]interface{}{"eth": service, "debug": service})
+		reason, err := client.TransactionRevertReason(context.Background(), tx.Hash())
+		if test.err != nil {
+			if err == nil || !strings.HasPrefix(err.Error(), test.err.Error()) {
+				t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+			}
+		} else if err != nil || reason != test.reason {
+			t.Errorf("test %d: have %q %v, want %q", i, reason, err, test.reason)
+		}
+
+		if service.callBlock != "0x1" {
+			t.Errorf("test %d: replayed at %s, want the block before 0x2", i, service.callBlock)
+		}
+		if service.callArgs["from"] != strings.ToLower(from.Hex()) || service.callArgs["to"] != strings.ToLower(to.Hex()) ||
+			service.callArgs["data"] != "0xd09d" || service.callArgs["gas"] != "0xc350" {
+			t.Errorf("test %d: replay mismatch: %v", i, service.callArgs)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	ErrNotAuthorized        = errors.New("not authorized to sign this account")
+	ErrSignedTxMismatch     = errors.New("signed transaction differs from the one asked to sign")
+	ErrUnexpectedSignerTx   = errors.New("transaction is signed by another account")
+	ErrSignHashNotSupported = errors.New("signer doesn't support signing hash")
+)
+
+// Signer is everything the client needs from the sender of a transaction,
+// the private key may live in memory, in a keystore or in another process
+type Signer interface {
+	Address() common.Address
+	// SignTx signs tx with an eip155 signer for chainID
+	SignTx(chainID *big.Int, tx *types.Transaction) (*types.Transaction, error)
+	// SignHash returns the [R || S || V] signature of a 32 bytes hash,
+	// signers which can't sign a raw hash return ErrSignHashNotSupported
+	SignHash(hash []byte) ([]byte, error)
+	// Transactor signs for bound contracts with an eip155 signer for chainID
+	Transactor(chainID *big.Int) *bind.TransactOpts
+}
+
+// ContextSigner is a Signer whose signing may block, the client passes the
+// context of the call to it
+type ContextSigner interface {
+	Signer
+	SignTxContext(ctx context.Context, chainID *big.Int, tx *types.Transaction) (*types.Transaction, error)
+}
+
+func signTx(ctx context.Context, s Signer, chainID *big.Int, tx *types.Transaction) (*types.Transaction, error) {
+	if cs, ok := s.(ContextSigner); ok {
+		return cs.SignTxContext(ctx, chainID, tx)
+	}
+	return s.SignTx(chainID, tx)
+}
+
+func signerTransactor(ctx context.Context, s Signer, chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: s.Address(),
+		Signer: func(_ types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != s.Address() {
+				return nil, ErrNotAuthorized
+			}
+			return signTx(ctx, s, chainID, tx)
+		},
+		Context: ctx,
+	}
+}
+
+// KeyStoreSigner signs with an unlocked account of a keystore, the key is
+// only decrypted inside the keystore
+type KeyStoreSigner struct {
+	ks      *keystore.KeyStore
+	account accounts.Account
+}
+
+func NewKeyStoreSigner(ks *keystore.KeyStore, address common.Address) (*KeyStoreSigner, error) {
+	account, err := ks.Find(accounts.Account{Address: address})
+	if err != nil {
+		return nil, err
+	}
+	return &KeyStoreSigner{
+		ks:      ks,
+		account: account,
+	}, nil
+}
+
+func (s *KeyStoreSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *KeyStoreSigner) SignTx(chainID *big.Int, tx *types.Transaction) (*types.Transaction, error) {
+	return s.ks.SignTx(s.account, tx, chainID)
+}
+
+func (s *KeyStoreSigner) SignHash(hash []byte) ([]byte, error) {
+	return s.ks.SignHash(s.account, hash)
+}
+
+func (s *KeyStoreSigner) Transactor(chainID *big.Int) *bind.TransactOpts {
+	return signerTransactor(context.Background(), s, chainID)
+}
+
+// RemoteSigner asks a signer process over json rpc, like a node which holds
+// the unlocked account, to sign with eth_signTransaction. The returned
+// transaction is checked against the asked one and its sender. Raw hash
+// can't be signed remotely, only messages with SignMessage
+type RemoteSigner struct {
+	c       *Client
+	address common.Address
+}
+
+func NewRemoteSigner(rawurl string, address common.Address) (*RemoteSigner, error) {
+	c, err := Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{
+		c:       c,
+		address: address,
+	}, nil
+}
+
+func (s *RemoteSigner) Close() {
+	s.c.Close()
+}
+
+// SetTimeout sets the deadline of signing requests made without one
+func (s *RemoteSigner) SetTimeout(timeout time.Duration) {
+	s.c.SetTimeout(timeout)
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *RemoteSigner) SignTx(chainID *big.Int, tx *types.Transaction) (*types.Transaction, error) {
+	return s.SignTxContext(context.Background(), chainID, tx)
+}
+
+func (s *RemoteSigner) SignTxContext(ctx context.Context, chainID *big.Int, tx *types.Transaction) (*types.Transaction, error) {
+	args := map[string]interface{}{
+		"from":     s.address,
+		"gas":      (*hexutil.Big)(tx.Gas()),
+		"gasPrice": (*hexutil.Big)(tx.GasPrice()),
+		"value":    (*hexutil.Big)(tx.Value()),
+		"nonce":    hexutil.Uint64(tx.Nonce()),
+		"data":     hexutil.Bytes(tx.Data()),
+	}
+	if tx.To() != nil {
+		args["to"] = tx.To()
+	}
+
+	var result struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := s.c.call(ctx, &result, "eth_signTransaction", args); err != nil {
+		return nil, err
+	}
+
+	signedTx := new(types.Transaction)
+	if err := rlp.DecodeBytes(result.Raw, signedTx); err != nil {
+		return nil, err
+	}
+	if err := s.verify(chainID, tx, signedTx); err != nil {
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// verify checks the remote signer signed tx as asked, for chainID and with
+// the key of its address
+func (s *RemoteSigner) verify(chainID *big.Int, tx, signedTx *types.Transaction) error {
+	if signedTx.Protected() == false || signedTx.ChainId().Cmp(chainID) != 0 {
+		return fmt.Errorf("%s: remote signer uses chain id %v", ErrChainIDMismatch.Error(), signedTx.ChainId())
+	}
+	if signedTx.Nonce() != tx.Nonce() ||
+		sameAddress(signedTx.To(), tx.To()) == false ||
+		signedTx.Value().Cmp(tx.Value()) != 0 ||
+		signedTx.Gas().Cmp(tx.Gas()) != 0 ||
+		signedTx.GasPrice().Cmp(tx.GasPrice()) != 0 ||
+		bytes.Equal(signedTx.Data(), tx.Data()) == false {
+		return ErrSignedTxMismatch
+	}
+
+	sender, err := types.Sender(types.NewEIP155Signer(chainID), signedTx)
+	if err != nil {
+		return err
+	}
+	if sender != s.address {
+		return fmt.Errorf("%s: %x", ErrUnexpectedSignerTx.Error(), sender)
+	}
+	return nil
+}
+
+func sameAddress(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// SignMessage signs the eip191 personal message msg with eth_sign, V of the
+// signature is 27 or 28 like SignMessage
+func (s *RemoteSigner) SignMessage(ctx context.Context, msg []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	if err := s.c.call(ctx, &signature, "eth_sign", s.address, hexutil.Bytes(msg)); err != nil {
+		return nil, err
+	}
+	if len(signature) != signatureLength {
+		return nil, ErrInvalidSignature
+	}
+	if signature[signatureLength-1] < 27 {
+		signature[signatureLength-1] += 27
+	}
+	return signature, nil
+}
+
+// SignHash isn't supported, eth_sign only signs messages with the eip191
+// prefix
+func (s *RemoteSigner) SignHash(hash []byte) ([]byte, error) {
+	return nil, ErrSignHashNotSupported
+}
+
+func (s *RemoteSigner) Transactor(chainID *big.Int) *bind.TransactOpts {
+	return signerTransactor(context.Background(), s, chainID)
+}
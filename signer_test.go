@@ -0,0 +1,193 @@
+package ethclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+type SignTxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Big    `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Data     hexutil.Bytes   `json:"data"`
+}
+
+// SignerService signs like a node holding key, tamper changes the
+// transaction before it's signed
+type SignerService struct {
+	key     *ecdsa.PrivateKey
+	chainID *big.Int
+	tamper  func(args *SignTxArgs)
+	delay   time.Duration
+}
+
+func (s *SignerService) SignTransaction(args SignTxArgs) (map[string]hexutil.Bytes, error) {
+	time.Sleep(s.delay)
+	if s.tamper != nil {
+		s.tamper(&args)
+	}
+
+	var tx *types.Transaction
+	if args.To == nil {
+		tx = types.NewContractCreation(uint64(args.Nonce), args.Value.ToInt(), args.Gas.ToInt(), args.GasPrice.ToInt(), args.Data)
+	} else {
+		tx = types.NewTransaction(uint64(args.Nonce), *args.To, args.Value.ToInt(), args.Gas.ToInt(), args.GasPrice.ToInt(), args.Data)
+	}
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(s.chainID), s.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rlp.EncodeToBytes(signedTx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]hexutil.Bytes{"raw": raw}, nil
+}
+
+func (s *SignerService) Sign(address common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	signature, err := crypto.Sign(MessageHash(data), s.key)
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+func newTestRemoteSigner(t *testing.T, service *SignerService) *RemoteSigner {
+	client := newTestClient(t, map[string]interface{}{"eth": service})
+	return &RemoteSigner{
+		c:       client,
+		address: crypto.PubkeyToAddress(service.key.PublicKey),
+	}
+}
+
+func TestRemoteSignerSignTx(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	chainID := big.NewInt(DefaultChainID)
+
+	tests := []struct {
+		service *SignerService
+		err     error
+	}{
+		{&SignerService{key: key, chainID: chainID}, nil},
+		{&SignerService{key: key, chainID: big.NewInt(1)}, ErrChainIDMismatch},
+		{&SignerService{key: key, chainID: chainID, tamper: func(args *SignTxArgs) { args.Nonce += 1 }}, ErrSignedTxMismatch},
+		{&SignerService{key: key, chainID: chainID, tamper: func(args *SignTxArgs) { args.Data = []byte{1} }}, ErrSignedTxMismatch},
+		{&SignerService{key: key, chainID: chainID, tamper: func(args *SignTxArgs) { args.To = nil }}, ErrSignedTxMismatch},
+		{&SignerService{key: key, chainID: chainID, tamper: func(args *SignTxArgs) { args.Value = (*hexutil.Big)(big.NewInt(2)) }}, ErrSignedTxMismatch},
+	}
+	to := common.HexToAddress("0x01")
+	tx := types.NewTransaction(3, to, big.NewInt(1), big.NewInt(21000), big.NewInt(1), nil)
+	for i, test := range tests {
+		signer := newTestRemoteSigner(t, test.service)
+		signedTx, err := signer.SignTx(chainID, tx)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("test %d: sign: %v", i, err)
+			} else if signedTx.Nonce() != tx.Nonce() || *signedTx.To() != to {
+				t.Errorf("test %d: signed transaction mismatch", i)
+			}
+		} else if err == nil || !containsError(err, test.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+
+	// the key of the node isn't the one of the signer address
+	signer := newTestRemoteSigner(t, &SignerService{key: otherKey, chainID: chainID})
+	signer.address = crypto.PubkeyToAddress(key.PublicKey)
+	if _, err := signer.SignTx(chainID, tx); err == nil || !containsError(err, ErrUnexpectedSignerTx) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrUnexpectedSignerTx)
+	}
+}
+
+func TestRemoteSignerTimeout(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(DefaultChainID)
+	signer := newTestRemoteSigner(t, &SignerService{key: key, chainID: chainID, delay: 500 * time.Millisecond})
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), big.NewInt(21000), big.NewInt(1), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := signer.SignTxContext(ctx, chainID, tx); err == nil {
+		t.Fatal("signing didn't honour the context")
+	}
+
+	signer.SetTimeout(50 * time.Millisecond)
+	if _, err := signer.SignTx(chainID, tx); err == nil {
+		t.Fatal("signing didn't honour the timeout")
+	}
+}
+
+func TestRemoteSignerSignMessage(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := newTestRemoteSigner(t, &SignerService{key: key})
+
+	msg := []byte("hello")
+	signature, err := signer.SignMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("sign message: %v", err)
+	}
+	if !VerifyMessage(signer.Address(), msg, signature) {
+		t.Fatal("message signature not verified")
+	}
+}
+
+func TestSignerImplementations(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(DefaultChainID)
+	account, _ := GenerateAccount()
+	remote := newTestRemoteSigner(t, &SignerService{key: key, chainID: chainID})
+
+	for _, signer := range []Signer{account, &KeyStoreSigner{}, remote} {
+		if opts := signer.Transactor(chainID); opts.From != signer.Address() {
+			t.Errorf("%T: transactor of another account", signer)
+		}
+	}
+
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), big.NewInt(21000), big.NewInt(1), nil)
+	signedTx, err := account.Transactor(chainID).Signer(types.HomesteadSigner{}, account.Address(), tx)
+	if err != nil {
+		t.Fatalf("transactor sign: %v", err)
+	} else if signedTx.ChainId().Cmp(chainID) != 0 {
+		t.Errorf("transactor chain id mismatch: have %v, want %v", signedTx.ChainId(), chainID)
+	}
+
+	// eth_sign can't sign a raw hash
+	if _, err := remote.SignHash(make([]byte, 32)); err != ErrSignHashNotSupported {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrSignHashNotSupported)
+	}
+	if _, err := SignMessage(remote, []byte("hello")); err != ErrSignHashNotSupported {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrSignHashNotSupported)
+	}
+}
+
+func TestAccountDeprecatedSigning(t *testing.T) {
+	account, _ := GenerateAccount()
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), big.NewInt(21000), big.NewInt(1), nil)
+
+	signedTx, err := account.SignTransaction(types.HomesteadSigner{}, tx)
+	if err != nil {
+		t.Fatalf("sign transaction: %v", err)
+	}
+	if sender, err := types.Sender(types.HomesteadSigner{}, signedTx); err != nil || sender != account.Address() {
+		t.Fatalf("sender mismatch: have %x %v, want %x", sender, err, account.Address())
+	}
+}
+
+func containsError(err, target error) bool {
+	return err == target || strings.HasPrefix(err.Error(), target.Error())
+}
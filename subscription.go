@@ -0,0 +1,243 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	minResubscribeDelay = time.Second
+	maxResubscribeDelay = 30 * time.Second
+
+	// most headers fetched to fill the gap after a reconnect, older missed
+	// headers are skipped
+	maxHeadGap = 1024
+	// headers fetched by one batch request while filling a gap
+	headGapBatch = 128
+
+	// retries of SubscribeHeader, whose subscription can't be unsubscribed
+	subscribeHeaderRetries = 5
+)
+
+var (
+	errSubscriptionClosed = errors.New("subscription is closed by server")
+)
+
+// HeadSubscribeOpts tunes how HeadSubscription reconnects
+type HeadSubscribeOpts struct {
+	// MaxRetries ends the subscription after this many reconnects in a row
+	// delivered no header, zero retries forever
+	MaxRetries int
+}
+
+// HeadSubscription delivers new heads in order without gaps. When the
+// connection is broken, it dials the endpoint again with backoff and
+// fetches the headers missed meanwhile by number, at most maxHeadGap of
+// them. Transports without subscription support, like http, are polled.
+type HeadSubscription struct {
+	client  *Client
+	opts    HeadSubscribeOpts
+	headers chan *types.Header
+	err     chan error
+	last    *types.Header
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (ec *Client) SubscribeHeads(ctx context.Context) *HeadSubscription {
+	return ec.SubscribeHeadsWithOpts(ctx, nil)
+}
+
+func (ec *Client) SubscribeHeadsWithOpts(ctx context.Context, opts *HeadSubscribeOpts) *HeadSubscription {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &HeadSubscription{
+		client:  ec,
+		headers: make(chan *types.Header),
+		err:     make(chan error, 1),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	if opts != nil {
+		s.opts = *opts
+	}
+	go s.loop(ctx)
+	return s
+}
+
+// Headers is closed when the subscription ends
+func (s *HeadSubscription) Headers() <-chan *types.Header {
+	return s.headers
+}
+
+// Err receives the error which ended the subscription: the error of ctx, or
+// the last connection error once MaxRetries is exceeded. Nothing is sent
+// after Unsubscribe, the channel is closed when the subscription ends
+func (s *HeadSubscription) Err() <-chan error {
+	return s.err
+}
+
+func (s *HeadSubscription) Unsubscribe() {
+	s.closeOnce.Do(func() {
+		s.cancel()
+		<-s.done
+	})
+}
+
+func (s *HeadSubscription) loop(ctx context.Context) {
+	defer close(s.done)
+	defer close(s.err)
+	defer close(s.headers)
+
+	delay := minResubscribeDelay
+	failures := 0
+	for {
+		delivered := s.last
+		err := s.run(ctx)
+		if s.last != delivered {
+			delay = minResubscribeDelay
+			failures = 0
+		}
+
+		if ctx.Err() != nil {
+			if ctx.Err() != context.Canceled {
+				s.err <- ctx.Err()
+			}
+			return
+		}
+		if failures += 1; s.opts.MaxRetries > 0 && failures > s.opts.MaxRetries {
+			s.err <- err
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() != context.Canceled {
+				s.err <- ctx.Err()
+			}
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxResubscribeDelay {
+			delay = maxResubscribeDelay
+		}
+	}
+}
+
+// run serves the subscription over a new connection until it fails
+func (s *HeadSubscription) run(ctx context.Context) error {
+	client, err := s.client.redial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	heads := make(chan *types.Header, 16)
+	sub, err := client.SubscribeNewHeadContext(ctx, heads)
+	if err == rpc.ErrNotificationsUnsupported {
+		return s.poll(ctx, client)
+	} else if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	// catch up the heads missed before subscribing
+	if err := s.deliverHead(ctx, client); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case h := <-heads:
+			if err := s.deliver(ctx, client, h); err != nil {
+				return err
+			}
+		case err := <-sub.Err():
+			if err == nil {
+				err = errSubscriptionClosed
+			}
+			return err
+		}
+	}
+}
+
+func (s *HeadSubscription) poll(ctx context.Context, client *Client) error {
+	ticker := time.NewTicker(WaitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.deliverHead(ctx, client); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *HeadSubscription) deliverHead(ctx context.Context, client *Client) error {
+	head, err := client.HeaderByNumberContext(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return s.deliver(ctx, client, head)
+}
+
+// deliver sends h after the headers between the last delivered one and h, a
+// header no newer than the last one is only sent if it's from a reorg. Only
+// the maxHeadGap headers right before h are filled
+func (s *HeadSubscription) deliver(ctx context.Context, client *Client, h *types.Header) error {
+	if s.last != nil {
+		if h.Number.Cmp(s.last.Number) <= 0 {
+			if h.Hash() == s.last.Hash() {
+				return nil
+			}
+		} else if next := new(big.Int).Add(s.last.Number, big.NewInt(1)); h.Number.Cmp(next) > 0 {
+			from, to := next.Uint64(), h.Number.Uint64()-1
+			if to-from+1 > maxHeadGap {
+				from = to + 1 - maxHeadGap
+			}
+			for ; from <= to; from += headGapBatch {
+				end := from + headGapBatch - 1
+				if end > to {
+					end = to
+				}
+				missing, err := client.HeadersByRange(ctx, from, end)
+				if err != nil {
+					return err
+				}
+				for _, r := range missing {
+					if r.Err != nil {
+						return r.Err
+					}
+					if err := s.send(ctx, r.Header); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return s.send(ctx, h)
+}
+
+func (s *HeadSubscription) send(ctx context.Context, h *types.Header) error {
+	select {
+	case s.headers <- h:
+		s.last = h
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,100 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// HeadChain serves eth_getBlockByNumber for a chain whose head can be moved
+type HeadChain struct {
+	mu   sync.Mutex
+	head int64
+}
+
+func (c *HeadChain) setHead(head int64) {
+	c.mu.Lock()
+	c.head = head
+	c.mu.Unlock()
+}
+
+func (c *HeadChain) GetBlockByNumber(number rpc.BlockNumber, full bool) *types.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := int64(number)
+	if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		n = c.head
+	}
+	if n > c.head {
+		return nil
+	}
+	return &types.Header{
+		Number:     big.NewInt(n),
+		Difficulty: big.NewInt(1),
+		GasLimit:   big.NewInt(0),
+		GasUsed:    big.NewInt(0),
+		Time:       big.NewInt(n),
+		Extra:      []byte{},
+	}
+}
+
+func receiveHeader(t *testing.T, sub *HeadSubscription) *types.Header {
+	select {
+	case h := <-sub.Headers():
+		return h
+	case err := <-sub.Err():
+		t.Fatalf("subscription ended: %v", err)
+	case <-time.After(3 * WaitPollInterval):
+		t.Fatal("no header delivered")
+	}
+	return nil
+}
+
+func TestHeadSubscriptionFillsGap(t *testing.T) {
+	chain := &HeadChain{head: 3}
+	client := newTestClient(t, map[string]interface{}{"eth": chain})
+
+	sub := client.SubscribeHeads(context.Background())
+	if h := receiveHeader(t, sub); h.Number.Int64() != 3 {
+		t.Fatalf("first head mismatch: have %v, want 3", h.Number)
+	}
+	chain.setHead(7)
+	for want := int64(4); want <= 7; want++ {
+		if h := receiveHeader(t, sub); h.Number.Int64() != want {
+			t.Fatalf("head mismatch: have %v, want %d", h.Number, want)
+		}
+	}
+
+	sub.Unsubscribe()
+	if _, ok := <-sub.Headers(); ok {
+		t.Fatal("headers channel open after unsubscribe")
+	}
+	if err, ok := <-sub.Err(); ok {
+		t.Fatalf("unexpected error after unsubscribe: %v", err)
+	}
+}
+
+func TestHeadSubscriptionMaxRetries(t *testing.T) {
+	client := newTestClient(t, nil)
+	client.url = "http://127.0.0.1:1"
+
+	sub := client.SubscribeHeadsWithOpts(context.Background(), &HeadSubscribeOpts{MaxRetries: 1})
+	defer sub.Unsubscribe()
+	select {
+	case err := <-sub.Err():
+		if err == nil {
+			t.Fatal("subscription ended without error")
+		}
+	case <-time.After(2 * maxResubscribeDelay):
+		t.Fatal("subscription not ended after max retries")
+	}
+	if _, ok := <-sub.Headers(); ok {
+		t.Fatal("headers channel open after failure")
+	}
+}
@@ -0,0 +1,167 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const callTracer = "callTracer"
+
+// TraceConfig of debug_traceTransaction and debug_traceCall, Tracer is the
+// name of a builtin tracer or javascript code. TracerConfig is passed to the
+// tracer, like *CallTracerConfig
+type TraceConfig struct {
+	DisableStorage bool        `json:"disableStorage,omitempty"`
+	DisableStack   bool        `json:"disableStack,omitempty"`
+	DisableMemory  bool        `json:"disableMemory,omitempty"`
+	Tracer         string      `json:"tracer,omitempty"`
+	TracerConfig   interface{} `json:"tracerConfig,omitempty"`
+	Timeout        string      `json:"timeout,omitempty"`
+}
+
+// CallTracerConfig of callTracer, nodes before geth 1.11 ignore it
+type CallTracerConfig struct {
+	// OnlyTopCall leaves out the internal calls
+	OnlyTopCall bool `json:"onlyTopCall,omitempty"`
+	// WithLog fills the logs of each call
+	WithLog bool `json:"withLog,omitempty"`
+}
+
+// StructLog is the evm state before an opcode runs, Error is a string, or
+// an object on old nodes
+type StructLog struct {
+	Pc      uint64            `json:"pc"`
+	Op      string            `json:"op"`
+	Gas     uint64            `json:"gas"`
+	GasCost uint64            `json:"gasCost"`
+	Depth   int               `json:"depth"`
+	Error   interface{}       `json:"error,omitempty"`
+	Stack   []string          `json:"stack,omitempty"`
+	Memory  []string          `json:"memory,omitempty"`
+	Storage map[string]string `json:"storage,omitempty"`
+}
+
+// ExecutionTrace is the output of the default struct logger
+type ExecutionTrace struct {
+	Gas         uint64      `json:"gas"`
+	Failed      bool        `json:"failed"`
+	ReturnValue string      `json:"returnValue"`
+	StructLogs  []StructLog `json:"structLogs"`
+}
+
+// CallFrame is one call of the callTracer output, Calls are the internal
+// calls it makes
+type CallFrame struct {
+	Type         string         `json:"type"`
+	From         common.Address `json:"from"`
+	To           common.Address `json:"to"`
+	Value        *hexutil.Big   `json:"value,omitempty"`
+	Gas          hexutil.Uint64 `json:"gas"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Input        hexutil.Bytes  `json:"input"`
+	Output       hexutil.Bytes  `json:"output,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	Calls        []CallFrame    `json:"calls,omitempty"`
+	Logs         []CallLog      `json:"logs,omitempty"`
+}
+
+// CallLog is a log emitted by a call, Position is the number of internal
+// calls made before it
+type CallLog struct {
+	Address  common.Address `json:"address"`
+	Topics   []common.Hash  `json:"topics"`
+	Data     hexutil.Bytes  `json:"data"`
+	Position hexutil.Uint   `json:"position"`
+}
+
+func (f *CallFrame) Failed() bool {
+	return f.Error != ""
+}
+
+// Reason returns the revert reason, which old nodes don't report so it's
+// decoded from the output
+func (f *CallFrame) Reason() string {
+	if f.RevertReason != "" {
+		return f.RevertReason
+	}
+	reason, _ := UnpackRevert(f.Output)
+	return reason
+}
+
+func (ec *Client) TraceTransaction(txHash common.Hash, config *TraceConfig) (*ExecutionTrace, error) {
+	return ec.TraceTransactionContext(context.Background(), txHash, config)
+}
+
+func (ec *Client) TraceTransactionContext(ctx context.Context, txHash common.Hash, config *TraceConfig) (*ExecutionTrace, error) {
+	var result ExecutionTrace
+	if err := ec.call(ctx, &result, "debug_traceTransaction", txHash, traceConfigArg(config)); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TraceTransactionCalls returns the call tree of transaction by callTracer
+func (ec *Client) TraceTransactionCalls(txHash common.Hash) (*CallFrame, error) {
+	return ec.TraceTransactionCallsContext(context.Background(), txHash)
+}
+
+func (ec *Client) TraceTransactionCallsContext(ctx context.Context, txHash common.Hash) (*CallFrame, error) {
+	return ec.TraceTransactionCallsWithOpts(ctx, txHash, nil)
+}
+
+func (ec *Client) TraceTransactionCallsWithOpts(ctx context.Context, txHash common.Hash, opts *CallTracerConfig) (*CallFrame, error) {
+	var result CallFrame
+	if err := ec.call(ctx, &result, "debug_traceTransaction", txHash, callTraceConfig(opts)); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TraceCall runs msg at block like eth_call and traces it
+func (ec *Client) TraceCall(msg ethereum.CallMsg, blockNumber *big.Int, config *TraceConfig) (*ExecutionTrace, error) {
+	return ec.TraceCallContext(context.Background(), msg, blockNumber, config)
+}
+
+func (ec *Client) TraceCallContext(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, config *TraceConfig) (*ExecutionTrace, error) {
+	var result ExecutionTrace
+	if err := ec.call(ctx, &result, "debug_traceCall", toCallArg(msg), toBlockNumArg(blockNumber), traceConfigArg(config)); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (ec *Client) TraceCallCalls(msg ethereum.CallMsg, blockNumber *big.Int) (*CallFrame, error) {
+	return ec.TraceCallCallsContext(context.Background(), msg, blockNumber)
+}
+
+func (ec *Client) TraceCallCallsContext(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (*CallFrame, error) {
+	return ec.TraceCallCallsWithOpts(ctx, msg, blockNumber, nil)
+}
+
+func (ec *Client) TraceCallCallsWithOpts(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, opts *CallTracerConfig) (*CallFrame, error) {
+	var result CallFrame
+	if err := ec.call(ctx, &result, "debug_traceCall", toCallArg(msg), toBlockNumArg(blockNumber), callTraceConfig(opts)); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func callTraceConfig(opts *CallTracerConfig) *TraceConfig {
+	config := &TraceConfig{Tracer: callTracer}
+	if opts != nil {
+		config.TracerConfig = opts
+	}
+	return config
+}
+
+func traceConfigArg(config *TraceConfig) *TraceConfig {
+	if config == nil {
+		return new(TraceConfig)
+	}
+	return config
+}
@@ -0,0 +1,153 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// callTracer output of a call reverting in an internal call, in the format
+// geth returns it
+const callTracerResult = `{
+	"type": "CALL",
+	"from": "0x71562b71999873db5b286df957af199ec94617f7",
+	"to": "0x7dbd46c0b9a4b80d5f9d4b3dc10cbc2bbbe6f7d4",
+	"value": "0x0",
+	"gas": "0x7a120",
+	"gasUsed": "0x6196",
+	"input": "0xd09de08a",
+	"output": "0x08c379a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000004626f6f6d00000000000000000000000000000000000000000000000000000000",
+	"error": "execution reverted",
+	"calls": [
+		{
+			"type": "STATICCALL",
+			"from": "0x7dbd46c0b9a4b80d5f9d4b3dc10cbc2bbbe6f7d4",
+			"to": "0x0000000000000000000000000000000000000004",
+			"gas": "0x76f24",
+			"gasUsed": "0x18",
+			"input": "0x1234",
+			"output": "0x1234"
+		},
+		{
+			"type": "DELEGATECALL",
+			"from": "0x7dbd46c0b9a4b80d5f9d4b3dc10cbc2bbbe6f7d4",
+			"to": "0x1111111111111111111111111111111111111111",
+			"gas": "0x76e00",
+			"gasUsed": "0x2f4",
+			"input": "0x",
+			"error": "execution reverted",
+			"revertReason": "boom",
+			"logs": [
+				{
+					"address": "0x7dbd46c0b9a4b80d5f9d4b3dc10cbc2bbbe6f7d4",
+					"topics": ["0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"],
+					"data": "0x01",
+					"position": "0x0"
+				}
+			]
+		}
+	]
+}`
+
+// struct logger output, Error was an object before geth 1.8
+const structLoggerResult = `{
+	"gas": 21164,
+	"failed": true,
+	"returnValue": "",
+	"structLogs": [
+		{"pc": 0, "op": "PUSH1", "gas": 78836, "gasCost": 3, "depth": 1, "stack": [], "memory": []},
+		{"pc": 2, "op": "SSTORE", "gas": 78833, "gasCost": 20000, "depth": 1,
+			"stack": ["0x0", "0x1"], "storage": {"0000000000000000000000000000000000000000000000000000000000000000": "0000000000000000000000000000000000000000000000000000000000000001"}},
+		{"pc": 3, "op": "INVALID", "gas": 58833, "gasCost": 0, "depth": 1, "error": {}}
+	]
+}`
+
+func TestDecodeCallFrame(t *testing.T) {
+	var frame CallFrame
+	if err := json.Unmarshal([]byte(callTracerResult), &frame); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if frame.Type != "CALL" || frame.Gas != 0x7a120 || frame.GasUsed != 0x6196 || frame.Value.ToInt().Sign() != 0 {
+		t.Errorf("top call mismatch: %+v", frame)
+	}
+	if !frame.Failed() || frame.Reason() != "boom" {
+		t.Errorf("top call failure mismatch: failed %v, reason %q", frame.Failed(), frame.Reason())
+	}
+	if len(frame.Calls) != 2 {
+		t.Fatalf("internal call count mismatch: have %d, want 2", len(frame.Calls))
+	}
+
+	static, delegate := frame.Calls[0], frame.Calls[1]
+	if static.Failed() || static.Value != nil || hexutil.Encode(static.Output) != "0x1234" {
+		t.Errorf("static call mismatch: %+v", static)
+	}
+	if !delegate.Failed() || delegate.Reason() != "boom" || len(delegate.Output) != 0 {
+		t.Errorf("delegate call mismatch: %+v", delegate)
+	}
+	if len(delegate.Logs) != 1 || delegate.Logs[0].Address != frame.To || len(delegate.Logs[0].Topics) != 1 {
+		t.Errorf("delegate call logs mismatch: %+v", delegate.Logs)
+	}
+}
+
+func TestDecodeExecutionTrace(t *testing.T) {
+	var trace ExecutionTrace
+	if err := json.Unmarshal([]byte(structLoggerResult), &trace); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if trace.Gas != 21164 || !trace.Failed || len(trace.StructLogs) != 3 {
+		t.Fatalf("trace mismatch: %+v", trace)
+	}
+	sstore := trace.StructLogs[1]
+	if sstore.Op != "SSTORE" || sstore.GasCost != 20000 || len(sstore.Stack) != 2 || len(sstore.Storage) != 1 {
+		t.Errorf("sstore mismatch: %+v", sstore)
+	}
+	if trace.StructLogs[0].Error != nil || trace.StructLogs[2].Error == nil {
+		t.Errorf("error mismatch: %v, %v", trace.StructLogs[0].Error, trace.StructLogs[2].Error)
+	}
+}
+
+// DebugService returns the callTracer result and keeps the config it gets
+type DebugService struct {
+	config *TraceConfig
+}
+
+func (s *DebugService) TraceTransaction(hash common.Hash, config *TraceConfig) (json.RawMessage, error) {
+	s.config = config
+	return json.RawMessage(callTracerResult), nil
+}
+
+func (s *DebugService) TraceCall(args map[string]interface{}, block string, config *TraceConfig) (json.RawMessage, error) {
+	s.config = config
+	return json.RawMessage(callTracerResult), nil
+}
+
+func TestTraceCallsConfig(t *testing.T) {
+	debug := &DebugService{}
+	client := newTestClient(t, map[string]interface{}{"debug": debug})
+
+	frame, err := client.TraceTransactionCallsWithOpts(context.Background(), common.Hash{1}, &CallTracerConfig{OnlyTopCall: true})
+	if err != nil {
+		t.Fatalf("trace transaction: %v", err)
+	}
+	if frame.Reason() != "boom" {
+		t.Errorf("reason mismatch: have %q, want boom", frame.Reason())
+	}
+	tracerConfig, _ := debug.config.TracerConfig.(map[string]interface{})
+	if debug.config.Tracer != callTracer || tracerConfig["onlyTopCall"] != true || tracerConfig["withLog"] != nil {
+		t.Errorf("config mismatch: %+v", debug.config)
+	}
+
+	if _, err := client.TraceCallCalls(ethereum.CallMsg{To: &common.Address{2}}, big.NewInt(1)); err != nil {
+		t.Fatalf("trace call: %v", err)
+	}
+	if debug.config.Tracer != callTracer || debug.config.TracerConfig != nil {
+		t.Errorf("config mismatch: %+v", debug.config)
+	}
+}
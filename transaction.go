@@ -0,0 +1,94 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// percent added on top of the estimated gas
+const DefaultGasMargin = 20
+
+var (
+	ErrFeeCapExceeded = errors.New("transaction fee exceeds the cap")
+)
+
+// SendOpts overrides how an outgoing transaction is built, nil fields are
+// filled by the client, gas limit by EstimateGas plus the gas margin and gas
+// price by SuggestGasPrice
+type SendOpts struct {
+	Value    *big.Int
+	GasLimit *big.Int
+	GasPrice *big.Int
+}
+
+func (c *Client) SetGasMargin(percent uint64) {
+	c.gasMargin = percent
+}
+
+// SetMaxFee rejects any transaction whose gas limit * gas price is bigger
+// than fee before it's signed, nil disables the check
+func (c *Client) SetMaxFee(fee *big.Int) {
+	c.maxFee = fee
+}
+
+func (c *Client) checkFee(tx *types.Transaction) error {
+	if c.maxFee == nil {
+		return nil
+	}
+
+	fee := new(big.Int).Mul(tx.Gas(), tx.GasPrice())
+	if fee.Cmp(c.maxFee) > 0 {
+		return fmt.Errorf("%s: fee %v cap %v", ErrFeeCapExceeded.Error(), fee, c.maxFee)
+	}
+	return nil
+}
+
+// to is nil for contract creation
+func (c *Client) newTransaction(ctx context.Context, from common.Address, to *common.Address, nonce uint64, data []byte, opts *SendOpts) (*types.Transaction, error) {
+	if opts == nil {
+		opts = &SendOpts{}
+	}
+
+	value := opts.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	gasPrice := opts.GasPrice
+	if gasPrice == nil {
+		suggested, err := c.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		gasPrice = suggested
+	}
+
+	gasLimit := opts.GasLimit
+	if gasLimit == nil {
+		msg := ethereum.CallMsg{From: from, To: to, Value: value, Data: data}
+		estimated, err := c.EstimateGas(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		gasLimit = new(big.Int).Mul(estimated, new(big.Int).SetUint64(100+c.gasMargin))
+		gasLimit.Div(gasLimit, big.NewInt(100))
+	}
+
+	var tx *types.Transaction
+	if to == nil {
+		tx = types.NewContractCreation(nonce, value, gasLimit, gasPrice, data)
+	} else {
+		tx = types.NewTransaction(nonce, *to, value, gasLimit, gasPrice, data)
+	}
+
+	if err := c.checkFee(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,287 @@
+package ethclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const eip712Domain = "EIP712Domain"
+
+var (
+	ErrInvalidTypedData = errors.New("invalid typed data")
+
+	arrayPattern  = regexp.MustCompile(`^(.+)\[([0-9]*)\]$`)
+	bytesNPattern = regexp.MustCompile(`^bytes([0-9]+)$`)
+	intPattern    = regexp.MustCompile(`^(u?)int([0-9]*)$`)
+)
+
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is the eip712 json used by eth_signTypedData
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]interface{}      `json:"domain"`
+	Message     map[string]interface{}      `json:"message"`
+}
+
+func ParseTypedData(data []byte) (*TypedData, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var typedData TypedData
+	if err := decoder.Decode(&typedData); err != nil {
+		return nil, err
+	}
+
+	if _, ok := typedData.Types[eip712Domain]; ok == false {
+		return nil, fmt.Errorf("%s: no %s type", ErrInvalidTypedData.Error(), eip712Domain)
+	} else if _, ok := typedData.Types[typedData.PrimaryType]; ok == false {
+		return nil, fmt.Errorf("%s: unknown primary type %s", ErrInvalidTypedData.Error(), typedData.PrimaryType)
+	}
+	return &typedData, nil
+}
+
+// Hash returns keccak256("\x19\x01" || domainSeparator || hashStruct(message))
+func (td *TypedData) Hash() ([]byte, error) {
+	domainSeparator, err := td.HashStruct(eip712Domain, td.Domain)
+	if err != nil {
+		return nil, err
+	}
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, messageHash), nil
+}
+
+func (td *TypedData) HashStruct(typeName string, data map[string]interface{}) ([]byte, error) {
+	encoded, err := td.encodeData(typeName, data)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(encoded), nil
+}
+
+func (td *TypedData) TypeHash(typeName string) []byte {
+	return crypto.Keccak256([]byte(td.EncodeType(typeName)))
+}
+
+// EncodeType returns the primary type followed by the referenced struct
+// types sorted by name, like Mail(Person from,Person to)Person(string name)
+func (td *TypedData) EncodeType(typeName string) string {
+	deps := td.dependencies(typeName, map[string]bool{})
+	sort.Strings(deps)
+
+	var buf bytes.Buffer
+	for _, name := range append([]string{typeName}, deps...) {
+		fields := make([]string, len(td.Types[name]))
+		for i, field := range td.Types[name] {
+			fields[i] = field.Type + " " + field.Name
+		}
+		buf.WriteString(name + "(" + strings.Join(fields, ",") + ")")
+	}
+	return buf.String()
+}
+
+func (td *TypedData) dependencies(typeName string, found map[string]bool) []string {
+	var deps []string
+	for _, field := range td.Types[typeName] {
+		fieldType := elementType(field.Type)
+		if _, ok := td.Types[fieldType]; ok == false || found[fieldType] || fieldType == typeName {
+			continue
+		}
+		found[fieldType] = true
+		deps = append(deps, fieldType)
+		deps = append(deps, td.dependencies(fieldType, found)...)
+	}
+	return deps
+}
+
+// elementType strips the array dimensions of typeName, like Person[2][]
+func elementType(typeName string) string {
+	for {
+		match := arrayPattern.FindStringSubmatch(typeName)
+		if match == nil {
+			return typeName
+		}
+		typeName = match[1]
+	}
+}
+
+func (td *TypedData) encodeData(typeName string, data map[string]interface{}) ([]byte, error) {
+	fields, ok := td.Types[typeName]
+	if ok == false {
+		return nil, fmt.Errorf("%s: unknown type %s", ErrInvalidTypedData.Error(), typeName)
+	}
+
+	encoded := td.TypeHash(typeName)
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if ok == false {
+			return nil, fmt.Errorf("%s: %s has no field %s", ErrInvalidTypedData.Error(), typeName, field.Name)
+		}
+		v, err := td.encodeValue(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %s", typeName, field.Name, err.Error())
+		}
+		encoded = append(encoded, v...)
+	}
+	return encoded, nil
+}
+
+func (td *TypedData) encodeValue(typeName string, value interface{}) ([]byte, error) {
+	// T[] or T[n], the last dimension is the outer one
+	if match := arrayPattern.FindStringSubmatch(typeName); match != nil {
+		items, ok := value.([]interface{})
+		if ok == false {
+			return nil, ErrInvalidTypedData
+		}
+		if match[2] != "" {
+			if size, err := strconv.Atoi(match[2]); err != nil || size != len(items) {
+				return nil, fmt.Errorf("%s: %s has %d items", ErrInvalidTypedData.Error(), typeName, len(items))
+			}
+		}
+		var encoded []byte
+		for _, item := range items {
+			v, err := td.encodeValue(match[1], item)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, v...)
+		}
+		return crypto.Keccak256(encoded), nil
+	}
+
+	if _, ok := td.Types[typeName]; ok {
+		data, ok := value.(map[string]interface{})
+		if ok == false {
+			return nil, ErrInvalidTypedData
+		}
+		return td.HashStruct(typeName, data)
+	}
+
+	switch typeName {
+	case "string":
+		s, ok := value.(string)
+		if ok == false {
+			return nil, ErrInvalidTypedData
+		}
+		return crypto.Keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := typedBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+	case "bool":
+		b, ok := value.(bool)
+		if ok == false {
+			return nil, ErrInvalidTypedData
+		}
+		if b {
+			return math.PaddedBigBytes(big.NewInt(1), 32), nil
+		}
+		return make([]byte, 32), nil
+	case "address":
+		s, ok := value.(string)
+		if ok == false || common.IsHexAddress(s) == false {
+			return nil, ErrInvalidTypedData
+		}
+		return common.LeftPadBytes(common.HexToAddress(s).Bytes(), 32), nil
+	}
+
+	if match := bytesNPattern.FindStringSubmatch(typeName); match != nil {
+		size, _ := strconv.Atoi(match[1])
+		b, err := typedBytes(value)
+		if err != nil {
+			return nil, err
+		} else if size == 0 || size > 32 || len(b) > size {
+			return nil, ErrInvalidTypedData
+		}
+		return common.RightPadBytes(b, 32), nil
+	}
+
+	if match := intPattern.FindStringSubmatch(typeName); match != nil {
+		n, err := typedInteger(value)
+		if err != nil {
+			return nil, err
+		}
+		if match[1] == "u" && n.Sign() < 0 {
+			return nil, ErrInvalidTypedData
+		}
+		return math.PaddedBigBytes(math.U256(n), 32), nil
+	}
+
+	return nil, fmt.Errorf("%s: unknown type %s", ErrInvalidTypedData.Error(), typeName)
+}
+
+func typedBytes(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if ok == false || strings.HasPrefix(s, "0x") == false {
+		return nil, ErrInvalidTypedData
+	}
+	return common.FromHex(s), nil
+}
+
+// integer could be json number, decimal or hex string
+func typedInteger(value interface{}) (*big.Int, error) {
+	var s string
+	switch v := value.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, ErrInvalidTypedData
+	}
+
+	n, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") {
+		n, ok = n.SetString(s[2:], 16)
+	} else {
+		n, ok = n.SetString(s, 10)
+	}
+	if ok == false {
+		return nil, ErrInvalidTypedData
+	}
+	return n, nil
+}
+
+// SignTypedData returns the eip712 signature with V 27 or 28
+func SignTypedData(signer Signer, typedData *TypedData) ([]byte, error) {
+	hash, err := typedData.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return signHash(signer, hash)
+}
+
+func RecoverTypedData(typedData *TypedData, signature []byte) (common.Address, error) {
+	hash, err := typedData.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return recoverHash(hash, signature)
+}
+
+func VerifyTypedData(address common.Address, typedData *TypedData, signature []byte) bool {
+	signer, err := RecoverTypedData(typedData, signature)
+	return err == nil && signer == address
+}
@@ -0,0 +1,140 @@
+package ethclient
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// the Mail example of the eip712 specification
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestTypedDataMail(t *testing.T) {
+	td, err := ParseTypedData([]byte(mailTypedData))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if got, want := td.EncodeType("Mail"), "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; got != want {
+		t.Errorf("encode type mismatch: have %s, want %s", got, want)
+	}
+	if got, want := hexutil.Encode(td.TypeHash("Mail")), "0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"; got != want {
+		t.Errorf("type hash mismatch: have %s, want %s", got, want)
+	}
+
+	domainSeparator, err := td.HashStruct(eip712Domain, td.Domain)
+	if err != nil {
+		t.Fatalf("hash domain: %v", err)
+	}
+	if got, want := hexutil.Encode(domainSeparator), "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"; got != want {
+		t.Errorf("domain separator mismatch: have %s, want %s", got, want)
+	}
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		t.Fatalf("hash message: %v", err)
+	}
+	if got, want := hexutil.Encode(messageHash), "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"; got != want {
+		t.Errorf("message hash mismatch: have %s, want %s", got, want)
+	}
+	hash, err := td.Hash()
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if got, want := hexutil.Encode(hash), "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; got != want {
+		t.Errorf("hash mismatch: have %s, want %s", got, want)
+	}
+
+	key, _ := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+	signer := NewAccountFromKey(key)
+	if want := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"); signer.Address() != want {
+		t.Fatalf("cow address mismatch: have %x, want %x", signer.Address(), want)
+	}
+	signature, err := SignTypedData(signer, td)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	want := "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "1c"
+	if got := hexutil.Encode(signature); got != want {
+		t.Errorf("signature mismatch: have %s, want %s", got, want)
+	}
+	if recovered, err := RecoverTypedData(td, signature); err != nil || recovered != signer.Address() {
+		t.Errorf("recovered signer mismatch: have %x %v, want %x", recovered, err, signer.Address())
+	}
+}
+
+func TestTypedDataArrays(t *testing.T) {
+	td := &TypedData{
+		Types: map[string][]TypedDataField{
+			eip712Domain: {},
+			"Person":     {{Name: "name", Type: "string"}},
+			"Group": {
+				{Name: "members", Type: "Person[2]"},
+				{Name: "scores", Type: "uint8[2][]"},
+			},
+		},
+	}
+	if got, want := td.EncodeType("Group"), "Group(Person[2] members,uint8[2][] scores)Person(string name)"; got != want {
+		t.Errorf("encode type mismatch: have %s, want %s", got, want)
+	}
+
+	alice := map[string]interface{}{"name": "alice"}
+	bob := map[string]interface{}{"name": "bob"}
+	scores := []interface{}{[]interface{}{"1", "2"}, []interface{}{"3", "4"}}
+
+	if _, err := td.HashStruct("Group", map[string]interface{}{
+		"members": []interface{}{alice, bob},
+		"scores":  scores,
+	}); err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+
+	invalid := []map[string]interface{}{
+		{"members": []interface{}{alice}, "scores": scores},
+		{"members": []interface{}{alice, bob}, "scores": []interface{}{[]interface{}{"1"}}},
+	}
+	for i, data := range invalid {
+		if _, err := td.HashStruct("Group", data); err == nil {
+			t.Errorf("test %d: wrong array length accepted", i)
+		}
+	}
+
+	// the fixed-size array is encoded like the dynamic one
+	fixed, _ := td.encodeValue("Person[2]", []interface{}{alice, bob})
+	dynamic, _ := td.encodeValue("Person[]", []interface{}{alice, bob})
+	if hexutil.Encode(fixed) != hexutil.Encode(dynamic) {
+		t.Errorf("fixed array encoding mismatch: have %x, want %x", fixed, dynamic)
+	}
+}
@@ -0,0 +1,148 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// used when the transport doesn't support subscription
+const WaitPollInterval = 2 * time.Second
+
+var (
+	ErrTransactionDropped = errors.New("transaction is dropped")
+)
+
+type MinedReceipt struct {
+	*types.Receipt
+	BlockHash   common.Hash
+	BlockNumber *big.Int
+}
+
+func (ec *Client) minedReceipt(ctx context.Context, txHash common.Hash) (*MinedReceipt, error) {
+	var raw json.RawMessage
+	if err := ec.call(ctx, &raw, "eth_getTransactionReceipt", txHash); err != nil {
+		return nil, err
+	} else if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.NotFound
+	}
+
+	var r *types.Receipt
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, err
+	}
+	var block struct {
+		BlockHash   common.Hash  `json:"blockHash"`
+		BlockNumber *hexutil.Big `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return nil, err
+	} else if block.BlockNumber == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return &MinedReceipt{
+		Receipt:     r,
+		BlockHash:   block.BlockHash,
+		BlockNumber: (*big.Int)(block.BlockNumber),
+	}, nil
+}
+
+// WaitMined waits until the transaction is included in a block
+func (ec *Client) WaitMined(ctx context.Context, txHash common.Hash) (*MinedReceipt, error) {
+	return ec.WaitConfirmed(ctx, txHash, 1)
+}
+
+// headWaiter wakes up on every new head, or every WaitPollInterval when
+// subscription isn't supported or is broken
+type headWaiter struct {
+	heads  chan *types.Header
+	sub    ethereum.Subscription
+	subErr <-chan error
+	ticker *time.Ticker
+}
+
+func (ec *Client) newHeadWaiter(ctx context.Context) *headWaiter {
+	w := &headWaiter{heads: make(chan *types.Header, 16)}
+	if sub, err := ec.SubscribeNewHeadContext(ctx, w.heads); err == nil {
+		w.sub = sub
+		w.subErr = sub.Err()
+	} else {
+		w.ticker = time.NewTicker(WaitPollInterval)
+	}
+	return w
+}
+
+func (w *headWaiter) Wait(ctx context.Context) error {
+	var poll <-chan time.Time
+	if w.ticker != nil {
+		poll = w.ticker.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.heads:
+	case <-poll:
+	case <-w.subErr:
+		w.subErr = nil
+		w.ticker = time.NewTicker(WaitPollInterval)
+	}
+	return nil
+}
+
+func (w *headWaiter) Close() {
+	if w.sub != nil {
+		w.sub.Unsubscribe()
+	}
+	if w.ticker != nil {
+		w.ticker.Stop()
+	}
+}
+
+// WaitConfirmed waits until the block including the transaction and
+// confirmations-1 blocks after it are on the canonical chain. If a reorg
+// removes the transaction from the chain, it waits for it to be mined again
+// unless the node has forgotten the transaction.
+func (ec *Client) WaitConfirmed(ctx context.Context, txHash common.Hash, confirmations uint64) (*MinedReceipt, error) {
+	waiter := ec.newHeadWaiter(ctx)
+	defer waiter.Close()
+
+	var mined *MinedReceipt
+	for {
+		receipt, err := ec.minedReceipt(ctx, txHash)
+		if err == nil {
+			mined = receipt
+			head, err := ec.HeaderByNumberContext(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			depth := new(big.Int).Sub(head.Number, receipt.BlockNumber)
+			if depth.Sign() >= 0 && depth.Uint64()+1 >= confirmations {
+				return receipt, nil
+			}
+		} else if err == ethereum.NotFound {
+			if mined != nil {
+				mined = nil
+				if _, _, err := ec.TransactionByHashContext(ctx, txHash); err == ethereum.NotFound {
+					return nil, ErrTransactionDropped
+				} else if err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			return nil, err
+		}
+
+		if err := waiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}